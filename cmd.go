@@ -12,7 +12,9 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/juju/ansiterm"
 	"github.com/juju/gnuflag"
@@ -116,6 +118,26 @@ type Context struct {
 	quiet        bool
 	verbose      bool
 	serialisable bool
+	assumeYes    bool
+	timings      []phaseTiming
+}
+
+// phaseTiming records how long a phase of running a command took.
+type phaseTiming struct {
+	phase    string
+	duration time.Duration
+}
+
+// addTiming records that the named phase, which began at start, is over.
+func (ctx *Context) addTiming(phase string, start time.Time) {
+	ctx.timings = append(ctx.timings, phaseTiming{phase, time.Since(start)})
+}
+
+// writeTimings writes the recorded phases and their durations to stderr.
+func (ctx *Context) writeTimings() {
+	for _, t := range ctx.timings {
+		fmt.Fprintf(ctx.Stderr, "%s took %v\n", t.phase, t.duration)
+	}
 }
 
 // Quiet reports whether the command is in "quiet" mode. When
@@ -185,11 +207,27 @@ func (ctx *Context) Verbosef(format string, params ...interface{}) {
 // WriteError will output the formatted text to the writer with
 // a colored ERROR like the logging would.
 func WriteError(writer io.Writer, err error) {
-	w := ansiterm.NewWriter(writer)
+	w := newColorWriter(writer)
 	ansiterm.Foreground(ansiterm.BrightRed).Fprintf(w, "ERROR")
 	fmt.Fprintf(w, " %s\n", err.Error())
 }
 
+// newColorWriter returns an ansiterm writer for w. Colors are only
+// written if w is a terminal and the NO_COLOR environment variable is not
+// set (see https://no-color.org).
+func newColorWriter(w io.Writer) *ansiterm.Writer {
+	writer := ansiterm.NewWriter(w)
+	if noColor() {
+		writer.SetColorCapable(false)
+	}
+	return writer
+}
+
+// noColor reports whether the user has asked for output without colors.
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
 // Getenv looks up an environment variable in the context. It mirrors
 // os.Getenv. An empty string is returned if the key is not set.
 func (ctx *Context) Getenv(key string) string {
@@ -349,15 +387,15 @@ func (i *Info) HelpWithSuperFlags(superF *gnuflag.FlagSet, f *gnuflag.FlagSet) [
 func handleCommandError(c Command, ctx *Context, err error, f *gnuflag.FlagSet) (rc int, done bool) {
 	switch err {
 	case nil:
-		return 0, false
+		return ExitSuccess, false
 	case gnuflag.ErrHelp:
 		ctx.Stdout.Write(c.Info().Help(f))
-		return 0, true
+		return ExitSuccess, true
 	case ErrSilent:
-		return 2, true
+		return ExitUsage, true
 	default:
 		WriteError(ctx.Stderr, err)
-		return 2, true
+		return exitCodeOr(err, ExitUsage), true
 	}
 }
 
@@ -371,29 +409,50 @@ func FlagAlias(c Command, akaDefault string) string {
 
 // Main runs the given Command in the supplied Context with the given
 // arguments, which should not include the command name. It returns a code
-// suitable for passing to os.Exit.
+// suitable for passing to os.Exit; see ExitCode for how it is chosen when
+// the command fails.
 func Main(c Command, ctx *Context, args []string) int {
+	defer logPanic()
 	f := gnuflag.NewFlagSetWithFlagKnownAs(c.Info().Name, gnuflag.ContinueOnError, FlagAlias(c, "flag"))
 	f.SetOutput(ioutil.Discard)
 	c.SetFlags(f)
-	if rc, done := handleCommandError(c, ctx, f.Parse(c.AllowInterspersedFlags(), args), f); done {
+	start := time.Now()
+	err := f.Parse(c.AllowInterspersedFlags(), args)
+	ctx.addTiming("parse", start)
+	if rc, done := handleCommandError(c, ctx, err, f); done {
 		return rc
 	}
 	// Since SuperCommands can also return gnuflag.ErrHelp errors, we need to
 	// handle both those types of errors as well as "real" errors.
-	if rc, done := handleCommandError(c, ctx, c.Init(f.Args()), f); done {
+	// A SuperCommand reads flag values from the context's environment
+	// while it is initialised.
+	if super, ok := c.(*SuperCommand); ok {
+		super.ctx = ctx
+	}
+	start = time.Now()
+	err = c.Init(f.Args())
+	ctx.addTiming("init", start)
+	if rc, done := handleCommandError(c, ctx, err, f); done {
 		return rc
 	}
+	warnDeprecatedFlags(ctx, f)
 	if err := c.Run(ctx); err != nil {
-		if IsRcPassthroughError(err) {
-			return err.(*RcPassthroughError).Code
-		}
-		if err != ErrSilent {
+		if !IsErrSilent(err) {
 			WriteError(ctx.Stderr, err)
 		}
-		return 1
+		return ExitCode(err)
+	}
+	return ExitSuccess
+}
+
+// logPanic writes a panic raised while running a command, along with its
+// stack, to the log before letting it continue. This means the panic is
+// not lost when stderr is not captured but a log file is.
+func logPanic() {
+	if r := recover(); r != nil {
+		logger.Criticalf("panic: %v\n%s", r, debug.Stack())
+		panic(r)
 	}
-	return 0
 }
 
 // DefaultContext returns a Context suitable for use in non-hosted situations.
@@ -14,7 +14,9 @@ import (
 
 	"github.com/juju/cmd"
 	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
 	"github.com/juju/gnuflag"
+	"github.com/juju/loggo"
 	"github.com/juju/testing"
 )
 
@@ -119,6 +121,22 @@ func (s *CmdSuite) TestMainRunSilentError(c *gc.C) {
 	c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
 }
 
+func (s *CmdSuite) TestMainPanicIsLogged(c *gc.C) {
+	var tw loggo.TestWriter
+	err := loggo.RegisterWriter("panic-test", &tw)
+	c.Assert(err, gc.IsNil)
+
+	ctx := cmdtesting.Context(c)
+	c.Assert(func() {
+		cmd.Main(&TestCommand{Name: "verb"}, ctx, []string{"--option", "panic"})
+	}, gc.PanicMatches, "BOOM!")
+
+	log := tw.Log()
+	c.Assert(log, gc.HasLen, 1)
+	c.Assert(log[0].Level, gc.Equals, loggo.CRITICAL)
+	c.Assert(log[0].Message, gc.Matches, `(?s)panic: BOOM!\ngoroutine .*util_test.go.*`)
+}
+
 func (s *CmdSuite) TestMainSuccess(c *gc.C) {
 	ctx := cmdtesting.Context(c)
 	result := cmd.Main(&TestCommand{Name: "verb"}, ctx, []string{"--option", "success!"})
@@ -202,6 +220,27 @@ func (s *CmdSuite) TestIsErrSilent(c *gc.C) {
 	c.Assert(cmd.IsErrSilent(fmt.Errorf("noisy")), gc.Equals, false)
 }
 
+func (s *CmdSuite) TestMainExitCodes(c *gc.C) {
+	// The exit codes are part of the public contract with init systems
+	// and scripts, so check the numbers Main actually exits with.
+	for _, test := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"--option", "ok"}, 0},
+		{[]string{"--option", "error"}, 1},
+		{[]string{"--unknown"}, 2},
+		{[]string{"--option", "transient-error"}, 75},
+		{[]string{"--option", "config-error"}, 78},
+		{[]string{"--option", "upgrade-error"}, 79},
+	} {
+		c.Logf("args %q", test.args)
+		ctx := cmdtesting.Context(c)
+		code := cmd.Main(&TestCommand{Name: "verb"}, ctx, test.args)
+		c.Check(code, gc.Equals, test.code)
+	}
+}
+
 func (s *CmdSuite) TestInfoHelp(c *gc.C) {
 	fs := gnuflag.NewFlagSet("", gnuflag.ContinueOnError)
 	s.assertFlagSetHelp(c, fs)
@@ -369,3 +408,32 @@ Details:
 command details
 `[1:])
 }
+
+func (s *CmdSuite) TestExitCode(c *gc.C) {
+	c.Assert(cmd.ExitCode(nil), gc.Equals, cmd.ExitSuccess)
+	c.Assert(cmd.ExitCode(fmt.Errorf("BAM!")), gc.Equals, cmd.ExitFatal)
+	c.Assert(cmd.ExitCode(cmd.ErrSilent), gc.Equals, cmd.ExitFatal)
+	c.Assert(cmd.ExitCode(cmd.NewRcPassthroughError(99)), gc.Equals, 99)
+	c.Assert(cmd.ExitCode(cmd.NewUsageError(fmt.Errorf("BAM!"))), gc.Equals, cmd.ExitUsage)
+	c.Assert(cmd.ExitCode(cmd.NewTransientError(fmt.Errorf("BAM!"))), gc.Equals, cmd.ExitTransient)
+	c.Assert(cmd.ExitCode(cmd.NewConfigError(fmt.Errorf("BAM!"))), gc.Equals, cmd.ExitConfigError)
+	c.Assert(cmd.ExitCode(cmd.NewNeedsUpgradeError(fmt.Errorf("BAM!"))), gc.Equals, cmd.ExitNeedsUpgrade)
+	err := errors.Annotate(cmd.NewTransientError(fmt.Errorf("BAM!")), "cannot connect")
+	c.Assert(cmd.ExitCode(err), gc.Equals, cmd.ExitTransient)
+}
+
+func (s *CmdSuite) TestMainRunTransientError(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	result := cmd.Main(&TestCommand{Name: "verb"}, ctx, []string{"--option", "transient-error"})
+	c.Assert(result, gc.Equals, cmd.ExitTransient)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "ERROR BAM!\n")
+}
+
+func (s *CmdSuite) TestMainRunTransientErrorFromSuperCommand(c *gc.C) {
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujutest"})
+	jc.Register(&TestCommand{Name: "verb"})
+	ctx := cmdtesting.Context(c)
+	result := cmd.Main(jc, ctx, []string{"verb", "--option", "transient-error"})
+	c.Assert(result, gc.Equals, cmd.ExitTransient)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "ERROR BAM!\n")
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/juju/gnuflag"
+)
+
+// completionNode describes a command for the purposes of completion.
+type completionNode struct {
+	// path holds the names of the subcommands leading to the command.
+	path        []string
+	flags       []string
+	subcommands []string
+	super       bool
+}
+
+const completionDoc = `
+Output a script that enables tab completion of the command's subcommands
+and flags in the given shell, which must be one of bash, zsh or fish.
+
+To enable completion for the current bash session:
+
+    source <(%[1]s completion bash)
+
+or for fish:
+
+    %[1]s completion fish | source
+`
+
+// completionCommand is a Command that writes shell completion scripts for
+// a SuperCommand.
+type completionCommand struct {
+	CommandBase
+	super *SuperCommand
+	shell string
+}
+
+func newCompletionCommand(super *SuperCommand) *completionCommand {
+	return &completionCommand{super: super}
+}
+
+func (c *completionCommand) Info() *Info {
+	return &Info{
+		Name:    "completion",
+		Args:    "bash|zsh|fish",
+		Purpose: "Output a shell completion script.",
+		Doc:     fmt.Sprintf(completionDoc, c.super.Name),
+	}
+}
+
+func (c *completionCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no shell specified")
+	}
+	c.shell, args = args[0], args[1:]
+	switch c.shell {
+	case "bash", "zsh", "fish":
+	default:
+		return fmt.Errorf("unknown shell %q, expected bash, zsh or fish", c.shell)
+	}
+	return CheckEmpty(args)
+}
+
+func (c *completionCommand) Run(ctx *Context) error {
+	nodes := completionNodes(c.super, nil, nil)
+	var script string
+	switch c.shell {
+	case "bash":
+		script = bashCompletion(c.super.Name, nodes)
+	case "zsh":
+		script = "autoload -U +X bashcompinit && bashcompinit\n" + bashCompletion(c.super.Name, nodes)
+	case "fish":
+		script = fishCompletion(c.super.Name, nodes)
+	}
+	_, err := fmt.Fprint(ctx.Stdout, script)
+	return err
+}
+
+// completionNodes returns the nodes for the given SuperCommand and all the
+// commands registered below it. The flags common to a SuperCommand are
+// offered for each of its subcommands as well.
+func completionNodes(super *SuperCommand, path, inherited []string) []completionNode {
+	f := gnuflag.NewFlagSetWithFlagKnownAs("", gnuflag.ContinueOnError, super.FlagKnownAs)
+	f.SetOutput(ioutil.Discard)
+	super.SetCommonFlags(f)
+	common := mergeNames(flagNames(f), inherited)
+
+	node := completionNode{path: path, flags: common, super: true}
+	for name, action := range super.subcmds {
+		if deprecated, _ := action.Deprecated(); !deprecated && !action.hidden {
+			node.subcommands = append(node.subcommands, name)
+		}
+	}
+	sort.Strings(node.subcommands)
+
+	var children []completionNode
+	for _, name := range node.subcommands {
+		action := super.subcmds[name]
+		childPath := append(append([]string(nil), path...), name)
+		if sub, ok := action.command.(*SuperCommand); ok {
+			children = append(children, completionNodes(sub, childPath, common)...)
+			continue
+		}
+		f := gnuflag.NewFlagSetWithFlagKnownAs("", gnuflag.ContinueOnError, super.FlagKnownAs)
+		f.SetOutput(ioutil.Discard)
+		action.command.SetFlags(f)
+		children = append(children, completionNode{
+			path:  childPath,
+			flags: mergeNames(flagNames(f), common),
+		})
+	}
+	return append([]completionNode{node}, children...)
+}
+
+// flagNames returns the names of the flags in f as they are typed on the
+// command line.
+func flagNames(f *gnuflag.FlagSet) []string {
+	var names []string
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		if len(flag.Name) == 1 {
+			names = append(names, "-"+flag.Name)
+		} else {
+			names = append(names, "--"+flag.Name)
+		}
+	})
+	sort.Strings(names)
+	return names
+}
+
+// mergeNames returns the sorted union of a and b.
+func mergeNames(a, b []string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range append(append([]string(nil), a...), b...) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completionFuncName returns a shell function name derived from the
+// command name.
+func completionFuncName(name string) string {
+	return "_" + strings.NewReplacer("-", "_", ".", "_").Replace(name) + "_completion"
+}
+
+func bashCompletion(name string, nodes []completionNode) string {
+	// Match the most deeply nested commands first, so that a
+	// SuperCommand's case doesn't hide those of its subcommands.
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return len(nodes[i].path) > len(nodes[j].path)
+	})
+	var buf bytes.Buffer
+	funcName := completionFuncName(name)
+	fmt.Fprintf(&buf, "%s() {\n", funcName)
+	buf.WriteString(`    local cur path word words
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    path=""
+    for word in "${COMP_WORDS[@]:1:COMP_CWORD-1}"; do
+        case "$word" in
+            -*) ;;
+            *) path="$path $word" ;;
+        esac
+    done
+    case "$path" in
+`)
+	for _, node := range nodes {
+		path := ""
+		if len(node.path) > 0 {
+			path = " " + strings.Join(node.path, " ")
+		}
+		pattern := fmt.Sprintf("%q", path)
+		if !node.super {
+			// Commands may take any number of arguments.
+			pattern += fmt.Sprintf("|%q*", path+" ")
+		}
+		words := append(append([]string(nil), node.subcommands...), node.flags...)
+		fmt.Fprintf(&buf, "        %s) words=%q ;;\n", pattern, strings.Join(words, " "))
+	}
+	buf.WriteString(`        *) words="" ;;
+    esac
+    COMPREPLY=($(compgen -W "$words" -- "$cur"))
+}
+`)
+	fmt.Fprintf(&buf, "complete -o default -F %s %s\n", funcName, name)
+	return buf.String()
+}
+
+func fishCompletion(name string, nodes []completionNode) string {
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		condition := "__fish_use_subcommand"
+		if len(node.path) > 0 {
+			condition = "__fish_seen_subcommand_from " + node.path[len(node.path)-1]
+		}
+		if len(node.subcommands) > 0 {
+			fmt.Fprintf(&buf, "complete -c %s -f -n '%s' -a '%s'\n", name, condition, strings.Join(node.subcommands, " "))
+		}
+		for _, flag := range node.flags {
+			option := "-l " + strings.TrimPrefix(flag, "--")
+			if !strings.HasPrefix(flag, "--") {
+				option = "-s " + strings.TrimPrefix(flag, "-")
+			}
+			fmt.Fprintf(&buf, "complete -c %s -n '%s' %s\n", name, condition, option)
+		}
+	}
+	return buf.String()
+}
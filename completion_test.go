@@ -0,0 +1,76 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type CompletionSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&CompletionSuite{})
+
+func (s *CompletionSuite) newSuperCommand() *cmd.SuperCommand {
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:       "jujud",
+		Log:        &cmd.Log{},
+		Completion: true,
+	})
+	jc.Register(&TestCommand{Name: "machine"})
+	sub := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "tool"})
+	sub.Register(&TestCommand{Name: "inspect"})
+	jc.Register(sub)
+	return jc
+}
+
+func (s *CompletionSuite) run(c *gc.C, shell string) string {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"completion", shell})
+	c.Assert(code, gc.Equals, 0)
+	return cmdtesting.Stdout(ctx)
+}
+
+func (s *CompletionSuite) TestBash(c *gc.C) {
+	script := s.run(c, "bash")
+	c.Check(script, gc.Matches, `(?s)_jujud_completion\(\) \{.*complete -o default -F _jujud_completion jujud\n`)
+	c.Check(script, gc.Matches, `(?s).*\n        ""\) words="completion help machine tool --debug .*`)
+	c.Check(script, gc.Matches, `(?s).*\n        " machine"\|" machine "\*\) words="[^"]*--option[^"]*" ;;\n.*`)
+	c.Check(script, gc.Matches, `(?s).*\n        " tool"\) words="help inspect [^"]*--debug[^"]*" ;;\n.*`)
+	c.Check(script, gc.Matches, `(?s).*\n        " tool inspect"\|" tool inspect "\*\) words="[^"]*--option[^"]*" ;;\n.*`)
+	// The nested command must be matched before the one containing it.
+	c.Check(script, gc.Matches, `(?s).*" tool inspect".*" tool"\).*`)
+}
+
+func (s *CompletionSuite) TestZsh(c *gc.C) {
+	script := s.run(c, "zsh")
+	c.Check(script, gc.Matches, `(?s)autoload -U \+X bashcompinit && bashcompinit\n_jujud_completion\(\) \{.*`)
+}
+
+func (s *CompletionSuite) TestFish(c *gc.C) {
+	script := s.run(c, "fish")
+	c.Check(script, gc.Matches, `(?s)complete -c jujud -f -n '__fish_use_subcommand' -a 'completion help machine tool'\n.*`)
+	c.Check(script, gc.Matches, `(?s).*\ncomplete -c jujud -n '__fish_use_subcommand' -l debug\n.*`)
+	c.Check(script, gc.Matches, `(?s).*\ncomplete -c jujud -n '__fish_seen_subcommand_from machine' -l option\n.*`)
+	c.Check(script, gc.Matches, `(?s).*\ncomplete -c jujud -f -n '__fish_seen_subcommand_from tool' -a 'help inspect'\n.*`)
+}
+
+func (s *CompletionSuite) TestBadShell(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"completion", "tcsh"})
+	c.Check(code, gc.Equals, 2)
+	c.Check(cmdtesting.Stderr(ctx), gc.Equals, `ERROR unknown shell "tcsh", expected bash, zsh or fish`+"\n")
+}
+
+func (s *CompletionSuite) TestNotRegisteredByDefault(c *gc.C) {
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujud"})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(jc, ctx, []string{"completion", "bash"})
+	c.Check(code, gc.Equals, 2)
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/juju/gnuflag"
+)
+
+// Deprecation is a DeprecationCheck for a command or flag that still
+// works, but is due to be removed.
+type Deprecation struct {
+	// Replacement is what should be used instead, if anything.
+	Replacement string
+
+	// RemovedIn is the version in which the command or flag will be
+	// removed, if known.
+	RemovedIn string
+}
+
+// Deprecated implements DeprecationCheck.
+func (d Deprecation) Deprecated() (bool, string) {
+	return true, d.Replacement
+}
+
+// Obsolete implements DeprecationCheck.
+func (d Deprecation) Obsolete() bool {
+	return false
+}
+
+// RemovalVersion returns the version in which the command or flag will be
+// removed.
+func (d Deprecation) RemovalVersion() string {
+	return d.RemovedIn
+}
+
+// deprecationWarning returns the warning written when the named command
+// or flag is used.
+func deprecationWarning(name, replacement, removedIn string) string {
+	warning := fmt.Sprintf("WARNING: %q is deprecated", name)
+	if removedIn != "" {
+		warning += fmt.Sprintf(" and will be removed in %s", removedIn)
+	}
+	if replacement != "" {
+		warning += fmt.Sprintf(", please use %q", replacement)
+	}
+	return warning
+}
+
+// removalVersion returns the version in which check says the command will
+// be removed, if it says so.
+func removalVersion(check DeprecationCheck) string {
+	if check, ok := check.(interface {
+		RemovalVersion() string
+	}); ok {
+		return check.RemovalVersion()
+	}
+	return ""
+}
+
+// deprecatedFlagValue wraps the value of a deprecated flag, so that a
+// warning can be written if it is set.
+type deprecatedFlagValue struct {
+	gnuflag.Value
+	deprecation Deprecation
+}
+
+// IsBoolFlag is there so that deprecated boolean flags can still be given
+// without a value.
+func (v *deprecatedFlagValue) IsBoolFlag() bool {
+	b, ok := v.Value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && b.IsBoolFlag()
+}
+
+// DeprecateFlag marks the named flag in f as deprecated, so that a warning
+// is written when a command is run with it. It should be called from a
+// command's SetFlags, after the flag has been added. The replacement is
+// given as the full flag, such as "--new-flag".
+func DeprecateFlag(f *gnuflag.FlagSet, name string, deprecation Deprecation) {
+	flag := f.Lookup(name)
+	if flag == nil {
+		panic(fmt.Sprintf("%q not found when deprecating flag", name))
+	}
+	flag.Value = &deprecatedFlagValue{
+		Value:       flag.Value,
+		deprecation: deprecation,
+	}
+}
+
+// warnDeprecatedFlags writes a warning for each deprecated flag that was
+// set when f was parsed.
+func warnDeprecatedFlags(ctx *Context, f *gnuflag.FlagSet) {
+	f.Visit(func(flag *gnuflag.Flag) {
+		value, ok := flag.Value.(*deprecatedFlagValue)
+		if !ok {
+			return
+		}
+		name := "--" + flag.Name
+		if len(flag.Name) == 1 {
+			name = "-" + flag.Name
+		}
+		ctx.Infof("%s", deprecationWarning(name, value.deprecation.Replacement, value.deprecation.RemovedIn))
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"github.com/juju/gnuflag"
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type DeprecationSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&DeprecationSuite{})
+
+// deprecatedFlagCommand has flags that have been replaced.
+type deprecatedFlagCommand struct {
+	TestCommand
+	verbose bool
+}
+
+func (c *deprecatedFlagCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.TestCommand.SetFlags(f)
+	f.StringVar(&c.Option, "old-option", "", "")
+	cmd.DeprecateFlag(f, "old-option", cmd.Deprecation{Replacement: "--option", RemovedIn: "3.0"})
+	f.BoolVar(&c.verbose, "chatty", false, "")
+	cmd.DeprecateFlag(f, "chatty", cmd.Deprecation{})
+}
+
+func (s *DeprecationSuite) TestCommandRemovalVersion(c *gc.C) {
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujud"})
+	jc.RegisterDeprecated(&TestCommand{Name: "machine-agent"}, cmd.Deprecation{
+		Replacement: "machine",
+		RemovedIn:   "3.0",
+	})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(jc, ctx, []string{"machine-agent", "--option", "done"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "done\n")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, `WARNING: "machine-agent" is deprecated and will be removed in 3.0, please use "machine"`+"\n")
+}
+
+func (s *DeprecationSuite) TestDeprecatedFlag(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(&deprecatedFlagCommand{TestCommand: TestCommand{Name: "verb"}}, ctx, []string{"--old-option", "done", "--chatty"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "done\n")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, `
+WARNING: "--chatty" is deprecated
+WARNING: "--old-option" is deprecated and will be removed in 3.0, please use "--option"
+`[1:])
+}
+
+func (s *DeprecationSuite) TestDeprecatedFlagNotUsed(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(&deprecatedFlagCommand{TestCommand: TestCommand{Name: "verb"}}, ctx, []string{"--option", "done"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+func (s *DeprecationSuite) TestDeprecatedSubcommandFlag(c *gc.C) {
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujud"})
+	jc.Register(&deprecatedFlagCommand{TestCommand: TestCommand{Name: "machine"}})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(jc, ctx, []string{"machine", "--old-option", "done"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "done\n")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, `WARNING: "--old-option" is deprecated and will be removed in 3.0, please use "--option"`+"\n")
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/gnuflag"
+)
+
+// documentationCommand is a hidden Command that renders the documentation
+// of a SuperCommand and everything registered below it.
+type documentationCommand struct {
+	CommandBase
+	super  *SuperCommand
+	format string
+	out    string
+}
+
+func newDocumentationCommand(super *SuperCommand) *documentationCommand {
+	return &documentationCommand{super: super}
+}
+
+func (c *documentationCommand) Info() *Info {
+	return &Info{
+		Name:    "documentation",
+		Purpose: "Generate the documentation for all commands.",
+		Doc: `
+Render a manual page for every command from its own help text. The pages
+are written to standard output unless --out names a directory to write
+them to, one file per command.
+`,
+	}
+}
+
+func (c *documentationCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.format, "format", "man", "Documentation format (man)")
+	f.StringVar(&c.out, "out", "", "Directory to write the documentation to")
+}
+
+func (c *documentationCommand) Init(args []string) error {
+	if c.format != "man" {
+		return fmt.Errorf("unknown format %q, expected %q", c.format, "man")
+	}
+	return CheckEmpty(args)
+}
+
+func (c *documentationCommand) Run(ctx *Context) error {
+	// As with help, the SuperCommand's info is wanted as if nothing had
+	// been selected.
+	c.super.action.command = nil
+	pages := manPages(c.super, c.super.Name)
+	names := make([]string, 0, len(pages))
+	for name := range pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c.out == "" {
+			if _, err := ctx.Stdout.Write(pages[name]); err != nil {
+				return err
+			}
+			continue
+		}
+		path := filepath.Join(ctx.AbsPath(c.out), name)
+		if err := ioutil.WriteFile(path, pages[name], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// manPages returns the man pages for super and each command registered
+// below it, keyed by file name.
+func manPages(super *SuperCommand, name string) map[string][]byte {
+	f := gnuflag.NewFlagSetWithFlagKnownAs(name, gnuflag.ContinueOnError, super.FlagKnownAs)
+	f.SetOutput(ioutil.Discard)
+	super.SetCommonFlags(f)
+	info := *super.Info()
+	info.Name = name
+	pages := map[string][]byte{
+		manFileName(name): manPage(&info, f),
+	}
+	for subName, action := range super.subcmds {
+		if action.alias != "" || action.hidden {
+			continue
+		}
+		if deprecated, _ := action.Deprecated(); deprecated {
+			continue
+		}
+		fullName := name + " " + subName
+		if sub, ok := action.command.(*SuperCommand); ok {
+			for file, page := range manPages(sub, fullName) {
+				pages[file] = page
+			}
+			continue
+		}
+		f := gnuflag.NewFlagSetWithFlagKnownAs(fullName, gnuflag.ContinueOnError, super.FlagKnownAs)
+		f.SetOutput(ioutil.Discard)
+		action.command.SetFlags(f)
+		info := *action.command.Info()
+		info.Name = fullName
+		pages[manFileName(fullName)] = manPage(&info, f)
+	}
+	return pages
+}
+
+// manFileName returns the name of the section 1 man page for the command
+// with the given full name.
+func manFileName(name string) string {
+	return strings.Replace(name, " ", "-", -1) + ".1"
+}
+
+// manPage renders info and the flags in f as a roff man page.
+func manPage(info *Info, f *gnuflag.FlagSet) []byte {
+	buf := &bytes.Buffer{}
+	title := strings.ToUpper(strings.Replace(info.Name, " ", "-", -1))
+	fmt.Fprintf(buf, ".TH %q 1\n", title)
+	fmt.Fprintf(buf, ".SH NAME\n%s", manEscape(strings.Replace(info.Name, " ", "-", -1)))
+	if info.Purpose != "" {
+		fmt.Fprintf(buf, " \\- %s", manEscape(strings.TrimSpace(info.Purpose)))
+	}
+	var flags []*gnuflag.Flag
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		flags = append(flags, flag)
+	})
+	buf.WriteString("\n.SH SYNOPSIS\n")
+	fmt.Fprintf(buf, ".B %s\n", manEscape(info.Name))
+	var synopsis []string
+	if len(flags) > 0 {
+		synopsis = append(synopsis, fmt.Sprintf("[%vs]", f.FlagKnownAs))
+	}
+	if info.Args != "" {
+		synopsis = append(synopsis, info.Args)
+	}
+	if len(synopsis) > 0 {
+		fmt.Fprintf(buf, "%s\n", manEscape(strings.Join(synopsis, " ")))
+	}
+	if doc := strings.TrimSpace(info.Doc); doc != "" {
+		buf.WriteString(".SH DESCRIPTION\n.nf\n")
+		for _, line := range strings.Split(doc, "\n") {
+			fmt.Fprintf(buf, "%s\n", manEscape(line))
+		}
+		buf.WriteString(".fi\n")
+	}
+	if len(info.Aliases) > 0 {
+		fmt.Fprintf(buf, ".SH ALIASES\n%s\n", manEscape(strings.Join(info.Aliases, ", ")))
+	}
+	if len(flags) > 0 {
+		fmt.Fprintf(buf, ".SH %sS\n", strings.ToUpper(f.FlagKnownAs))
+		for _, flag := range flags {
+			name := "--" + flag.Name
+			if len(flag.Name) == 1 {
+				name = "-" + flag.Name
+			}
+			fmt.Fprintf(buf, ".TP\n.B %s", manEscape(name))
+			if flag.DefValue != "" {
+				fmt.Fprintf(buf, " (= %s)", manEscape(flag.DefValue))
+			}
+			usage := flag.Usage
+			if usage == "" {
+				usage = "Same as above."
+			}
+			fmt.Fprintf(buf, "\n%s\n", manEscape(usage))
+		}
+	}
+	return buf.Bytes()
+}
+
+// manEscape escapes s so that roff renders it literally.
+func manEscape(s string) string {
+	s = strings.Replace(s, `\`, `\e`, -1)
+	s = strings.Replace(s, "-", `\-`, -1)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
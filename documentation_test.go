@@ -0,0 +1,114 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type DocumentationSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&DocumentationSuite{})
+
+func (s *DocumentationSuite) newSuperCommand() *cmd.SuperCommand {
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:          "jujud",
+		Purpose:       "Run the agent.",
+		Documentation: true,
+	})
+	jc.Register(&TestCommand{Name: "machine", Aliases: []string{"m"}})
+	sub := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "tool", Purpose: "Run a tool."})
+	sub.Register(&TestCommand{Name: "inspect"})
+	jc.Register(sub)
+	return jc
+}
+
+func (s *DocumentationSuite) TestManPages(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"documentation", "--out", "."})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "")
+
+	files, err := ioutil.ReadDir(ctx.Dir)
+	c.Assert(err, gc.IsNil)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	c.Assert(names, gc.DeepEquals, []string{
+		"jujud-help.1",
+		"jujud-machine.1",
+		"jujud-tool-help.1",
+		"jujud-tool-inspect.1",
+		"jujud-tool.1",
+		"jujud.1",
+	})
+
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "jujud-machine.1"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Equals, `.TH "JUJUD-MACHINE" 1
+.SH NAME
+jujud\-machine \- machine the juju
+.SH SYNOPSIS
+.B jujud machine
+[flags] <something>
+.SH DESCRIPTION
+.nf
+machine\-doc
+.fi
+.SH ALIASES
+m
+.SH FLAGS
+.TP
+.B \-\-option
+option\-doc
+`)
+
+	content, err = ioutil.ReadFile(filepath.Join(ctx.Dir, "jujud.1"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `(?s).TH "JUJUD" 1
+.SH NAME
+jujud \\- Run the agent.
+.SH SYNOPSIS
+.B jujud
+\[flags\] <command> ...
+.*    machine \\- machine the juju
+.*`)
+	// The documentation command itself is hidden.
+	c.Assert(string(content), gc.Not(gc.Matches), `(?s).*documentation.*`)
+}
+
+func (s *DocumentationSuite) TestStdout(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"documentation"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Matches, `(?s).TH "JUJUD-HELP" 1\n.*.TH "JUJUD" 1\n.*`)
+}
+
+func (s *DocumentationSuite) TestUnknownFormat(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"documentation", "--format", "html"})
+	c.Assert(code, gc.Equals, 2)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, `ERROR unknown format "html", expected "man"`+"\n")
+}
+
+func (s *DocumentationSuite) TestHiddenFromHelp(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"help", "commands"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `help     Show help on a command or other topic.
+m        Alias for 'machine'.
+machine  machine the juju
+tool     Run a tool.
+`)
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"github.com/juju/errors"
+)
+
+// Exit codes returned by Main. They are stable, so that init systems and
+// scripts can decide whether a failed command is worth restarting.
+const (
+	// ExitSuccess is returned when the command ran to completion.
+	ExitSuccess = 0
+
+	// ExitFatal is returned when the command failed and retrying it
+	// without intervention is not expected to help.
+	ExitFatal = 1
+
+	// ExitUsage is returned when the command line could not be parsed,
+	// or the command rejected its arguments.
+	ExitUsage = 2
+
+	// ExitTransient is returned when the command failed for a reason
+	// that may go away if it is retried later (EX_TEMPFAIL).
+	ExitTransient = 75
+
+	// ExitConfigError is returned when the command could not run because
+	// its configuration is invalid (EX_CONFIG).
+	ExitConfigError = 78
+
+	// ExitNeedsUpgrade is returned when the command cannot continue until
+	// the binary has been replaced with a newer version.
+	ExitNeedsUpgrade = 79
+)
+
+// ExitCodeError is an error that makes Main exit with Code rather than
+// ExitFatal. It is reported in the same way as the error it holds.
+type ExitCodeError struct {
+	Err  error
+	Code int
+}
+
+// Error implements error.
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+// NewExitCodeError returns an error that makes Main exit with the given
+// code after reporting err.
+func NewExitCodeError(code int, err error) error {
+	return &ExitCodeError{Err: err, Code: code}
+}
+
+// NewUsageError returns an error that makes Main exit with ExitUsage, for
+// a command that was not invoked correctly.
+func NewUsageError(err error) error {
+	return NewExitCodeError(ExitUsage, err)
+}
+
+// NewTransientError returns an error that makes Main exit with
+// ExitTransient, for a command that may succeed if it is run again later.
+func NewTransientError(err error) error {
+	return NewExitCodeError(ExitTransient, err)
+}
+
+// NewConfigError returns an error that makes Main exit with
+// ExitConfigError, for a command whose configuration is invalid.
+func NewConfigError(err error) error {
+	return NewExitCodeError(ExitConfigError, err)
+}
+
+// NewNeedsUpgradeError returns an error that makes Main exit with
+// ExitNeedsUpgrade, for a command that can't continue until the binary
+// has been replaced with a newer version.
+func NewNeedsUpgradeError(err error) error {
+	return NewExitCodeError(ExitNeedsUpgrade, err)
+}
+
+// ExitCode returns the code that Main exits with for err. An ExitCodeError
+// may have been annotated with errors.Annotate and the like on its way up.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+	switch err := errors.Cause(err).(type) {
+	case *ExitCodeError:
+		return err.Code
+	case *RcPassthroughError:
+		return err.Code
+	}
+	return ExitFatal
+}
+
+// exitCodeOr returns the code that Main exits with for err if it is an
+// ExitCodeError, and otherwise code.
+func exitCodeOr(err error, code int) int {
+	if _, ok := errors.Cause(err).(*ExitCodeError); ok {
+		return ExitCode(err)
+	}
+	return code
+}
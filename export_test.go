@@ -3,6 +3,35 @@
 
 package cmd
 
+import (
+	"io"
+	"time"
+
+	"github.com/juju/loggo"
+)
+
+var IsTerminal = &isTerminal
+
+const MaxRateLimitWindows = maxRateLimitWindows
+
 func NewVersionCommand(version string, versionDetail interface{}) Command {
 	return newVersionCommand(version, versionDetail)
 }
+
+func NewRotatingFile(path string, maxSize int64, maxBackups int, maxAge time.Duration, compress bool) (io.WriteCloser, error) {
+	return openRotatingFile(path, maxSize, maxBackups, maxAge, compress)
+}
+
+func NewSyslogWriter(writer syslogger) loggo.Writer {
+	return &syslogWriter{writer}
+}
+
+var ConnectSyslog = &connectSyslog
+
+// FakeConnectSyslog returns a replacement for ConnectSyslog that uses
+// writer rather than the syslog daemon.
+func FakeConnectSyslog(writer syslogger) func(facility, tag string) (*syslogWriter, error) {
+	return func(facility, tag string) (*syslogWriter, error) {
+		return &syslogWriter{writer}, nil
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/juju/gnuflag"
+	goyaml "gopkg.in/yaml.v2"
+)
+
+// flagDefaults holds the flag values read from a defaults file. Values
+// at the top level of the file apply to every command that has a flag
+// of that name; those in a section named after a subcommand apply to
+// that subcommand only, and take precedence.
+//
+//	log-file: /var/log/agent.log
+//	machine:
+//	  data-dir: /var/lib/agent
+type flagDefaults struct {
+	path     string
+	global   map[string]string
+	commands map[string]map[string]string
+}
+
+// readFlagDefaults reads the flag defaults file at path. If the file
+// does not exist and mustExist is false, no defaults are returned.
+func readFlagDefaults(path string, mustExist bool) (*flagDefaults, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && !mustExist {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := goyaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("cannot parse %q: %v", path, err)
+	}
+	defaults := &flagDefaults{
+		path:     path,
+		global:   make(map[string]string),
+		commands: make(map[string]map[string]string),
+	}
+	for key, value := range raw {
+		section, ok := value.(map[interface{}]interface{})
+		if !ok {
+			defaults.global[key] = fmt.Sprint(value)
+			continue
+		}
+		values := make(map[string]string)
+		for name, value := range section {
+			values[fmt.Sprint(name)] = fmt.Sprint(value)
+		}
+		defaults.commands[key] = values
+	}
+	return defaults, nil
+}
+
+// apply sets the flags in f for the given subcommand to their defaults,
+// other than those named in set, which have already been given on the
+// command line, and --help and --description.
+func (d *flagDefaults) apply(f *gnuflag.FlagSet, command string, set map[string]bool) error {
+	for name, value := range d.global {
+		if set[name] || isActionFlag(name) || f.Lookup(name) == nil {
+			continue
+		}
+		if err := f.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value %q for %s %q in %q: %v", value, f.FlagKnownAs, name, d.path, err)
+		}
+	}
+	for name, value := range d.commands[command] {
+		if set[name] || isActionFlag(name) {
+			continue
+		}
+		if f.Lookup(name) == nil {
+			return fmt.Errorf("%q has no %s %q, found in %q", command, f.FlagKnownAs, name, d.path)
+		}
+		if err := f.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value %q for %s %q in %q: %v", value, f.FlagKnownAs, name, d.path, err)
+		}
+	}
+	return nil
+}
+
+// setFlags returns the names of the flags given on the command line in
+// any of the flag sets, including the other names of those flags, such
+// as -v for --verbose, which share their Values.
+func setFlags(flagSets ...*gnuflag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	values := make(map[gnuflag.Value]bool)
+	for _, f := range flagSets {
+		if f == nil {
+			continue
+		}
+		f.Visit(func(flag *gnuflag.Flag) {
+			set[flag.Name] = true
+			if reflect.TypeOf(flag.Value).Comparable() {
+				values[flag.Value] = true
+			}
+		})
+	}
+	for _, f := range flagSets {
+		if f == nil {
+			continue
+		}
+		f.VisitAll(func(flag *gnuflag.Flag) {
+			if reflect.TypeOf(flag.Value).Comparable() && values[flag.Value] {
+				set[flag.Name] = true
+			}
+		})
+	}
+	return set
+}
+
+// flagEnvName returns the name of the environment variable that supplies
+// the value of the named flag.
+func flagEnvName(prefix, name string) string {
+	return prefix + "_" + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// lookupFlagEnv returns the value of the environment variable for the
+// named flag, if there is a prefix and the variable is not empty. The
+// context's environment takes precedence over the process's.
+func lookupFlagEnv(ctx *Context, prefix, name string) (string, bool) {
+	if prefix == "" {
+		return "", false
+	}
+	key := flagEnvName(prefix, name)
+	value := os.Getenv(key)
+	if ctx != nil {
+		if contextValue, ok := ctx.Env[key]; ok {
+			value = contextValue
+		}
+	}
+	return value, value != ""
+}
+
+// isActionFlag reports whether the named flag makes a SuperCommand do
+// something other than run the subcommand, such as show help, so that
+// it must only be given on the command line.
+func isActionFlag(name string) bool {
+	return name == "help" || name == "description"
+}
+
+// applyFlagEnv sets the flags in f from their environment variables,
+// other than those named in set. Single letter flags are left alone, as
+// they are short forms of others, and so are --help and --description.
+// So is --config, as its variable names the flag defaults file.
+func applyFlagEnv(ctx *Context, f *gnuflag.FlagSet, prefix string, set map[string]bool) error {
+	var err error
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		if err != nil || set[flag.Name] || len(flag.Name) == 1 || isActionFlag(flag.Name) || flag.Name == "config" {
+			return
+		}
+		value, ok := lookupFlagEnv(ctx, prefix, flag.Name)
+		if !ok {
+			return
+		}
+		if setErr := f.Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid value %q for %s %q in $%s: %v", value, f.FlagKnownAs, flag.Name, flagEnvName(prefix, flag.Name), setErr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,279 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/gnuflag"
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type FlagDefaultsSuite struct {
+	testing.LoggingCleanupSuite
+	path string
+	log  *cmd.Log
+}
+
+var _ = gc.Suite(&FlagDefaultsSuite{})
+
+func (s *FlagDefaultsSuite) SetUpTest(c *gc.C) {
+	s.LoggingCleanupSuite.SetUpTest(c)
+	s.path = filepath.Join(c.MkDir(), "defaults.yaml")
+}
+
+func (s *FlagDefaultsSuite) writeDefaults(c *gc.C, content string) {
+	err := ioutil.WriteFile(s.path, []byte(content), 0644)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *FlagDefaultsSuite) run(c *gc.C, args ...string) (int, *cmd.Context) {
+	// Each run starts logging afresh.
+	loggo.ResetWriters()
+	s.log = &cmd.Log{}
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:                 "jujud",
+		Log:                  s.log,
+		FlagDefaultsFilename: s.path,
+		FlagEnvPrefix:        "JUJUD",
+	})
+	jc.Register(&TestCommand{Name: "machine", Aliases: []string{"m"}})
+	jc.Register(&TestCommand{Name: "unit"})
+	ctx := cmdtesting.Context(c)
+	return cmd.Main(jc, ctx, args), ctx
+}
+
+func (s *FlagDefaultsSuite) TestGlobalDefault(c *gc.C) {
+	s.writeDefaults(c, "option: global\n")
+	code, ctx := s.run(c, "unit")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "global\n")
+}
+
+func (s *FlagDefaultsSuite) TestCommandSection(c *gc.C) {
+	s.writeDefaults(c, "option: global\nmachine:\n  option: machine\n")
+	code, ctx := s.run(c, "machine")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "machine\n")
+
+	// Aliases use the section of the command they refer to.
+	code, ctx = s.run(c, "m")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "machine\n")
+
+	code, ctx = s.run(c, "unit")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "global\n")
+}
+
+func (s *FlagDefaultsSuite) TestCommandLineWins(c *gc.C) {
+	s.writeDefaults(c, "option: global\nlogging-config: juju=DEBUG\n")
+	code, ctx := s.run(c, "--logging-config", "juju=INFO", "unit", "--option", "cli")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "cli\n")
+	c.Assert(s.log.Config, gc.Equals, "juju=INFO")
+}
+
+func (s *FlagDefaultsSuite) TestMissingFile(c *gc.C) {
+	code, ctx := s.run(c, "unit")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "\n")
+
+	code, ctx = s.run(c, "--config", s.path, "unit")
+	c.Assert(code, gc.Equals, 2)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, "ERROR open .*defaults.yaml: no such file or directory\n")
+}
+
+func (s *FlagDefaultsSuite) TestConfigFlag(c *gc.C) {
+	other := filepath.Join(filepath.Dir(s.path), "other.yaml")
+	err := ioutil.WriteFile(other, []byte("option: other\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	code, ctx := s.run(c, "--config", other, "unit")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "other\n")
+}
+
+func (s *FlagDefaultsSuite) TestUnknownFlagInSection(c *gc.C) {
+	s.writeDefaults(c, "unknown: ignored\nmachine:\n  bad: value\n")
+	code, _ := s.run(c, "unit")
+	c.Assert(code, gc.Equals, 0)
+
+	code, ctx := s.run(c, "machine")
+	c.Assert(code, gc.Equals, 2)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `ERROR "machine" has no flag "bad", found in ".*defaults.yaml"\n`)
+}
+
+func (s *FlagDefaultsSuite) TestInvalidValue(c *gc.C) {
+	s.writeDefaults(c, "verbose: sometimes\n")
+	code, ctx := s.run(c, "unit")
+	c.Assert(code, gc.Equals, 2)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `ERROR invalid value "sometimes" for flag "verbose" in ".*defaults.yaml": .*\n`)
+}
+
+func (s *FlagDefaultsSuite) TestEnvironment(c *gc.C) {
+	s.PatchEnvironment("JUJUD_OPTION", "env")
+	s.PatchEnvironment("JUJUD_LOGGING_CONFIG", "juju=TRACE")
+	code, ctx := s.run(c, "unit")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "env\n")
+	c.Assert(s.log.Config, gc.Equals, "juju=TRACE")
+}
+
+func (s *FlagDefaultsSuite) TestPrecedence(c *gc.C) {
+	s.writeDefaults(c, "option: file\nlogging-config: juju=DEBUG\n")
+	s.PatchEnvironment("JUJUD_OPTION", "env")
+	code, ctx := s.run(c, "unit")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "env\n")
+	c.Assert(s.log.Config, gc.Equals, "juju=DEBUG")
+
+	s.PatchEnvironment("JUJUD_LOGGING_CONFIG", "juju=TRACE")
+	code, ctx = s.run(c, "--logging-config", "juju=INFO", "unit", "--option", "cli")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "cli\n")
+	c.Assert(s.log.Config, gc.Equals, "juju=INFO")
+}
+
+func (s *FlagDefaultsSuite) TestConfigFromEnvironment(c *gc.C) {
+	other := filepath.Join(filepath.Dir(s.path), "other.yaml")
+	err := ioutil.WriteFile(other, []byte("option: other\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	s.PatchEnvironment("JUJUD_CONFIG", other)
+	code, ctx := s.run(c, "unit")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "other\n")
+}
+
+func (s *FlagDefaultsSuite) TestInvalidEnvironmentValue(c *gc.C) {
+	s.PatchEnvironment("JUJUD_VERBOSE", "sometimes")
+	code, ctx := s.run(c, "unit")
+	c.Assert(code, gc.Equals, 2)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `ERROR invalid value "sometimes" for flag "verbose" in \$JUJUD_VERBOSE: .*\n`)
+}
+
+func (s *FlagDefaultsSuite) TestCommandLineReplacesEnvironment(c *gc.C) {
+	// --verbose counts how often it is given, so applying the
+	// environment as well as the command line would make it -vv.
+	s.PatchEnvironment("JUJUD_VERBOSE", "true")
+	for _, args := range [][]string{
+		{"unit", "--verbose"},
+		{"unit", "-v"},
+		{"--verbose", "unit"},
+	} {
+		c.Logf("args %q", args)
+		code, _ := s.run(c, args...)
+		c.Assert(code, gc.Equals, 0)
+		c.Check(s.log.Verbosity, gc.Equals, 1)
+		c.Check(s.log.Debug, gc.Equals, false)
+	}
+	code, _ := s.run(c, "unit")
+	c.Assert(code, gc.Equals, 0)
+	c.Check(s.log.Verbosity, gc.Equals, 1)
+}
+
+func (s *FlagDefaultsSuite) TestCommandLineReplacesFile(c *gc.C) {
+	s.writeDefaults(c, "verbose: true\n")
+	code, _ := s.run(c, "unit", "--verbose")
+	c.Assert(code, gc.Equals, 0)
+	c.Check(s.log.Verbosity, gc.Equals, 1)
+	c.Check(s.log.Debug, gc.Equals, false)
+}
+
+// configCommand has a --config flag of its own.
+type configCommand struct {
+	cmd.CommandBase
+	config string
+}
+
+func (c *configCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "configure"}
+}
+
+func (c *configCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.config, "config", "", "")
+}
+
+func (c *configCommand) Run(ctx *cmd.Context) error {
+	fmt.Fprintln(ctx.Stdout, c.config)
+	return nil
+}
+
+func (s *FlagDefaultsSuite) TestConfigEnvironmentIgnoredForCommandFlag(c *gc.C) {
+	s.PatchEnvironment("JUJUD_CONFIG", "from-env")
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:          "jujud",
+		FlagEnvPrefix: "JUJUD",
+	})
+	jc.Register(&configCommand{})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(jc, ctx, []string{"configure"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "\n")
+}
+
+func (s *FlagDefaultsSuite) TestCommandConfigFlagLeavesDefaultsFile(c *gc.C) {
+	// The defaults file doesn't exist, which is fine as it wasn't named
+	// with the SuperCommand's --config, and $JUJUD_CONFIG still applies.
+	other := filepath.Join(filepath.Dir(s.path), "other.yaml")
+	err := ioutil.WriteFile(other, []byte("logging-config: juju=DEBUG\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	for _, env := range []string{"", other} {
+		c.Logf("JUJUD_CONFIG=%q", env)
+		s.PatchEnvironment("JUJUD_CONFIG", env)
+		loggo.ResetWriters()
+		log := &cmd.Log{}
+		jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+			Name:                 "jujud",
+			Log:                  log,
+			FlagDefaultsFilename: s.path,
+			FlagEnvPrefix:        "JUJUD",
+		})
+		jc.Register(&configCommand{})
+		ctx := cmdtesting.Context(c)
+		code := cmd.Main(jc, ctx, []string{"configure", "--config", "mine"})
+		c.Assert(code, gc.Equals, 0, gc.Commentf("stderr: %s", cmdtesting.Stderr(ctx)))
+		c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "mine\n")
+		if env != "" {
+			c.Assert(log.Config, gc.Equals, "juju=DEBUG")
+		}
+	}
+}
+
+func (s *FlagDefaultsSuite) TestHelpNotFromEnvironmentOrFile(c *gc.C) {
+	s.PatchEnvironment("JUJUD_HELP", "true")
+	s.PatchEnvironment("JUJUD_DESCRIPTION", "true")
+	code, ctx := s.run(c, "unit", "--option", "x")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "x\n")
+
+	s.PatchEnvironment("JUJUD_HELP", "")
+	s.PatchEnvironment("JUJUD_DESCRIPTION", "")
+	s.writeDefaults(c, "help: true\nunit:\n  description: true\n")
+	code, ctx = s.run(c, "unit", "--option", "x")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "x\n")
+}
+
+func (s *FlagDefaultsSuite) TestContextEnvironment(c *gc.C) {
+	s.PatchEnvironment("JUJUD_OPTION", "process")
+	loggo.ResetWriters()
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:          "jujud",
+		Log:           &cmd.Log{},
+		FlagEnvPrefix: "JUJUD",
+	})
+	jc.Register(&TestCommand{Name: "unit"})
+	ctx := cmdtesting.Context(c)
+	ctx.Setenv("JUJUD_OPTION", "context")
+	code := cmd.Main(jc, ctx, []string{"unit"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "context\n")
+}
@@ -21,6 +21,26 @@ type helpCommand struct {
 
 	target      *commandReference
 	targetSuper *SuperCommand
+	format      string
+}
+
+// commandHelp is the structured help for a command, output by
+// "help --format json".
+type commandHelp struct {
+	Name        string        `json:"name"`
+	Args        string        `json:"args,omitempty"`
+	Purpose     string        `json:"purpose,omitempty"`
+	Doc         string        `json:"doc,omitempty"`
+	Aliases     []string      `json:"aliases,omitempty"`
+	Flags       []flagHelp    `json:"flags,omitempty"`
+	Subcommands []commandHelp `json:"subcommands,omitempty"`
+}
+
+// flagHelp describes one of the flags of a command.
+type flagHelp struct {
+	Name    string `json:"name"`
+	Default string `json:"default,omitempty"`
+	Usage   string `json:"usage,omitempty"`
 }
 
 func (c *helpCommand) init() {
@@ -109,7 +129,20 @@ See also: topics
 	}
 }
 
+func (c *helpCommand) SetFlags(f *gnuflag.FlagSet) {
+	// The SuperCommand may already have a common --format flag, in which
+	// case help is always output as text.
+	if f.Lookup("format") == nil {
+		f.StringVar(&c.format, "format", "text", "Specify output format (text|json)")
+	}
+}
+
 func (c *helpCommand) Init(args []string) error {
+	switch c.format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("unknown help format %q, expected text or json", c.format)
+	}
 	if c.super.notifyHelp != nil {
 		c.super.notifyHelp(args)
 	}
@@ -200,7 +233,54 @@ func (c *helpCommand) getCommandHelp(super *SuperCommand, command Command, alias
 	return info.HelpWithSuperFlags(superf, f)
 }
 
+// getCommandHelpJSON returns the structured help for command, and for
+// any subcommands if it is a SuperCommand.
+func (c *helpCommand) getCommandHelpJSON(super *SuperCommand, command Command, name string) commandHelp {
+	info := command.Info()
+	f := gnuflag.NewFlagSetWithFlagKnownAs(name, gnuflag.ContinueOnError, super.FlagKnownAs)
+	command.SetFlags(f)
+	result := commandHelp{
+		Name:    name,
+		Args:    info.Args,
+		Purpose: strings.TrimSpace(info.Purpose),
+		Doc:     strings.TrimSpace(info.Doc),
+		Aliases: info.Aliases,
+	}
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		result.Flags = append(result.Flags, flagHelp{
+			Name:    flag.Name,
+			Default: flag.DefValue,
+			Usage:   flag.Usage,
+		})
+	})
+	sub, ok := command.(*SuperCommand)
+	if !ok {
+		return result
+	}
+	names := make([]string, 0, len(sub.subcmds))
+	for subName := range sub.subcmds {
+		names = append(names, subName)
+	}
+	sort.Strings(names)
+	for _, subName := range names {
+		action := sub.subcmds[subName]
+		if action.alias != "" || action.hidden {
+			continue
+		}
+		if deprecated, _ := action.Deprecated(); deprecated {
+			continue
+		}
+		result.Subcommands = append(result.Subcommands, c.getCommandHelpJSON(sub, action.command, name+" "+subName))
+	}
+	return result
+}
+
 func (c *helpCommand) Run(ctx *Context) error {
+	if c.format == "json" {
+		if help, ok := c.helpJSON(); ok {
+			return FormatJson(ctx.Stdout, help)
+		}
+	}
 	if c.super.showVersion {
 		v := newVersionCommand(c.super.version, c.super.versionDetail)
 		v.SetFlags(c.super.flags)
@@ -250,3 +330,34 @@ func (c *helpCommand) Run(ctx *Context) error {
 	}
 	return fmt.Errorf("unknown command or topic for %s", c.topic)
 }
+
+// helpJSON returns the structured help for the selected command or
+// topic, if there is one.
+func (c *helpCommand) helpJSON() (commandHelp, bool) {
+	// Commands are named as they are in the text output.
+	prefixed := func(super *SuperCommand, name string) string {
+		if super.usagePrefix != "" {
+			return super.usagePrefix + " " + name
+		}
+		return name
+	}
+	if c.target != nil {
+		name := c.target.name
+		if c.target.alias != "" {
+			name = c.target.alias
+		}
+		name = prefixed(c.targetSuper, c.targetSuper.Name+" "+name)
+		return c.getCommandHelpJSON(c.targetSuper, c.target.command, name), true
+	}
+	if c.topic == "" {
+		c.super.action.command = nil
+		return c.getCommandHelpJSON(c.super, c.super, prefixed(c.super, c.super.Name)), true
+	}
+	if topic, ok := c.topics[c.topic]; ok {
+		return commandHelp{
+			Name: c.topic,
+			Doc:  strings.TrimSpace(topic.long()),
+		}, true
+	}
+	return commandHelp{}, false
+}
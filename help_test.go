@@ -177,3 +177,35 @@ func (s *HelpCommandSuite) TestNotifyHelp(c *gc.C) {
 
 	c.Assert(called, jc.DeepEquals, [][]string{{"blah"}})
 }
+
+func (s *HelpCommandSuite) TestHelpJSON(c *gc.C) {
+	newSuper := func() *cmd.SuperCommand {
+		super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujud", Purpose: "Run the agent."})
+		super.Register(&TestCommand{Name: "machine", Aliases: []string{"m"}})
+		sub := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "tool", Purpose: "Run a tool."})
+		sub.Register(&TestCommand{Name: "inspect"})
+		super.Register(sub)
+		return super
+	}
+
+	ctx, err := cmdtesting.RunCommand(c, newSuper(), "help", "--format", "json", "machine")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `{"name":"jujud machine","args":"\u003csomething\u003e","purpose":"machine the juju","doc":"machine-doc","aliases":["m"],"flags":[{"name":"option","usage":"option-doc"}]}`+"\n")
+
+	ctx, err = cmdtesting.RunCommand(c, newSuper(), "help", "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Matches, `\{"name":"jujud","args":"\\u003ccommand\\u003e ...","purpose":"Run the agent.",.*"subcommands":\[`+
+		`\{"name":"jujud help",.*\},`+
+		`\{"name":"jujud machine",.*\},`+
+		`\{"name":"jujud tool",.*"subcommands":\[\{"name":"jujud tool help",.*\},\{"name":"jujud tool inspect",.*\}\]\}\]\}`+"\n")
+
+	ctx, err = cmdtesting.RunCommand(c, newSuper(), "help", "--format", "json", "topics")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Matches, `\{"name":"topics","doc":"commands .*"\}`+"\n")
+}
+
+func (s *HelpCommandSuite) TestHelpUnknownFormat(c *gc.C) {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujud"})
+	_, err := cmdtesting.RunCommand(c, super, "help", "--format", "xml")
+	c.Assert(err, gc.ErrorMatches, `unknown help format "xml", expected text or json`)
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/loggo"
+)
+
+// asyncItem is either an entry to be written, or a flush request to be
+// acknowledged once everything queued before it has been written.
+type asyncItem struct {
+	entry loggo.Entry
+	done  chan struct{}
+}
+
+// AsyncWriter is a loggo.Writer that queues entries and writes them to
+// another writer in the background, so that logging is not held up by a
+// slow destination. The queue is bounded; if it is full, entries are
+// dropped and a count of them is written once there is room again.
+//
+// The background goroutine runs until Close is called.
+type AsyncWriter struct {
+	writer    loggo.Writer
+	queue     chan asyncItem
+	dropped   int64
+	stop      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewAsyncWriter returns an AsyncWriter that queues up to size entries
+// for writer.
+func NewAsyncWriter(writer loggo.Writer, size int) *AsyncWriter {
+	w := &AsyncWriter{
+		writer:  writer,
+		queue:   make(chan asyncItem, size),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go w.loop()
+	return w
+}
+
+// Write implements loggo.Writer. Critical entries are written before
+// Write returns, as they are often the last thing logged before the
+// process exits.
+func (w *AsyncWriter) Write(entry loggo.Entry) {
+	select {
+	case <-w.stop:
+		return
+	default:
+	}
+	select {
+	case w.queue <- asyncItem{entry: entry}:
+	default:
+		atomic.AddInt64(&w.dropped, 1)
+	}
+	if entry.Level >= loggo.CRITICAL {
+		w.Flush()
+	}
+}
+
+// Flush waits until all the entries queued so far have been written.
+func (w *AsyncWriter) Flush() {
+	done := make(chan struct{})
+	select {
+	case w.queue <- asyncItem{done: done}:
+	case <-w.stopped:
+		return
+	}
+	select {
+	case <-done:
+	case <-w.stopped:
+	}
+}
+
+// Close writes the entries already queued and stops the background
+// goroutine. Entries written after Close are discarded.
+func (w *AsyncWriter) Close() {
+	w.closeOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.stopped
+}
+
+func (w *AsyncWriter) loop() {
+	defer close(w.stopped)
+	for {
+		select {
+		case item := <-w.queue:
+			w.write(item)
+		case <-w.stop:
+			for {
+				select {
+				case item := <-w.queue:
+					w.write(item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *AsyncWriter) write(item asyncItem) {
+	if dropped := atomic.SwapInt64(&w.dropped, 0); dropped > 0 {
+		w.writer.Write(loggo.Entry{
+			Level:     loggo.WARNING,
+			Module:    "cmd",
+			Timestamp: time.Now(),
+			Message:   fmt.Sprintf("dropped %d log records, the log destination is too slow", dropped),
+		})
+	}
+	if item.done != nil {
+		close(item.done)
+		return
+	}
+	w.writer.Write(item.entry)
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type AsyncSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&AsyncSuite{})
+
+// blockingWriter holds up every write until it is released.
+type blockingWriter struct {
+	loggo.TestWriter
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(entry loggo.Entry) {
+	<-w.release
+	w.TestWriter.Write(entry)
+}
+
+func (s *AsyncSuite) TestFlush(c *gc.C) {
+	target := &loggo.TestWriter{}
+	w := cmd.NewAsyncWriter(target, 10)
+	w.Write(rateLimitEntry("juju.worker", "one", 0))
+	w.Write(rateLimitEntry("juju.worker", "two", time.Second))
+	w.Flush()
+	c.Assert(messages(target.Log()), gc.DeepEquals, []string{
+		"juju.worker: one",
+		"juju.worker: two",
+	})
+}
+
+func (s *AsyncSuite) TestDoesNotBlock(c *gc.C) {
+	target := &blockingWriter{release: make(chan struct{})}
+	w := cmd.NewAsyncWriter(target, 2)
+	for i := 0; i < 5; i++ {
+		w.Write(rateLimitEntry("juju.worker", "flap", 0))
+	}
+	close(target.release)
+	w.Flush()
+	// Depending on whether the first record was picked up before the
+	// queue filled, either two or three records were dropped.
+	var written, dropped int
+	for _, entry := range target.Log() {
+		if entry.Module == "cmd" {
+			_, err := fmt.Sscanf(entry.Message, "dropped %d log records", &dropped)
+			c.Assert(err, gc.IsNil)
+			continue
+		}
+		written++
+	}
+	c.Assert(dropped, gc.Not(gc.Equals), 0)
+	c.Assert(written+dropped, gc.Equals, 5)
+}
+
+func (s *AsyncSuite) TestCriticalIsWrittenImmediately(c *gc.C) {
+	target := &loggo.TestWriter{}
+	w := cmd.NewAsyncWriter(target, 10)
+	entry := rateLimitEntry("juju.worker", "boom", 0)
+	entry.Level = loggo.CRITICAL
+	w.Write(entry)
+	c.Assert(messages(target.Log()), gc.DeepEquals, []string{"juju.worker: boom"})
+}
+
+func (s *LogSuite) TestAsyncLog(c *gc.C) {
+	l := &cmd.Log{Path: "foo.log", Config: "<root>=INFO", AsyncBufferSize: 10}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("hello")
+	l.Flush()
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "foo.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* INFO .* hello\n`)
+}
+
+func (s *AsyncSuite) TestClose(c *gc.C) {
+	target := &blockingWriter{release: make(chan struct{})}
+	w := cmd.NewAsyncWriter(target, 10)
+	w.Write(rateLimitEntry("juju.worker", "one", 0))
+	w.Write(rateLimitEntry("juju.worker", "two", time.Second))
+	close(target.release)
+	w.Close()
+	c.Assert(messages(target.Log()), gc.DeepEquals, []string{
+		"juju.worker: one",
+		"juju.worker: two",
+	})
+
+	// Once closed, entries are discarded and nothing blocks.
+	w.Write(rateLimitEntry("juju.worker", "three", 2*time.Second))
+	w.Flush()
+	w.Close()
+	c.Assert(target.Log(), gc.HasLen, 2)
+}
+
+func (s *LogSuite) TestAsyncLogRestart(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	l := &cmd.Log{Path: "foo.log", Config: "<root>=INFO", AsyncBufferSize: 10}
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("hello")
+
+	// Starting again writes out what was queued and closes the old file.
+	loggo.ResetWriters()
+	l.Path = "bar.log"
+	err = l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("goodbye")
+	l.Flush()
+
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "foo.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* INFO .* hello\n`)
+	content, err = ioutil.ReadFile(filepath.Join(ctx.Dir, "bar.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* INFO .* goodbye\n`)
+}
+
+func (s *LogSuite) TestAsyncLogRestartWithoutReset(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	l := &cmd.Log{Path: "foo.log", Config: "<root>=INFO", AsyncBufferSize: 10}
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("one")
+
+	// The writers registered by the first Start are replaced.
+	err = l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("two")
+	l.Flush()
+
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "foo.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* INFO .* one\n.* INFO .* two\n$`)
+}
@@ -4,9 +4,17 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/juju/ansiterm"
 	"github.com/juju/gnuflag"
@@ -27,28 +35,206 @@ type Log struct {
 	ShowLog       bool
 	Config        string
 
+	// ExtendedFlags specifies whether AddFlags also adds flags for the
+	// settings beyond the long-standing logging ones: --trace,
+	// --logging-config-file, --log-file-level, --show-log-level,
+	// --log-format, --log-time-format, --log-utc and --log-target. The
+	// settings can be used from code either way; the flags are opt-in
+	// so that they don't clash with flags a program defines itself.
+	ExtendedFlags bool
+
+	// Verbosity is the number of times -v or --verbose was given. Once
+	// sets Verbose; twice is equivalent to Debug, and three times also
+	// logs at TRACE.
+	Verbosity int
+
+	// Trace is like Debug, except that every module logs at TRACE,
+	// whatever Config and ConfigFile say, for this run only.
+	Trace bool
+
+	// ConfigFile is the path of a file holding per-module log levels,
+	// one module=level pair per line. Blank lines and lines starting
+	// with "#" are ignored. Levels specified in Config take precedence
+	// over those read from the file.
+	ConfigFile string
+
+	// FileLevel, if specified, is the minimum level of records written to
+	// the log file at Path. If it is lower than the level that would
+	// otherwise be logged, the root logger level is lowered to match.
+	FileLevel loggo.Level
+
+	// StderrLevel, if specified, is the minimum level of records written
+	// to stderr when ShowLog is set. If it is lower than the level that
+	// would otherwise be logged, the root logger level is lowered to
+	// match.
+	StderrLevel loggo.Level
+
+	// Tag, if set, identifies the process writing log records, for
+	// example an agent tag such as "machine-0". It is added to every
+	// record written to the log file, to stderr by ShowLog and to
+	// syslog, which lets records from processes that share a log
+	// destination be told apart. Tag is ignored when NewWriter is set.
+	Tag string
+
+	// TimeFormat specifies how timestamps are written in text log
+	// records. It is one of LogTimeRFC3339, LogTimeEpochMillis or a
+	// layout as understood by time.Format. If it is empty, loggo's
+	// default short time format is used. JSON records always use RFC3339
+	// timestamps in UTC.
+	TimeFormat string
+
+	// UTC specifies whether timestamps in text log records are written
+	// in UTC rather than in local time.
+	UTC bool
+
+	// Format selects how log records are written to the log file and,
+	// when ShowLog is set, to stderr. It is one of LogFormatText (the
+	// default if empty) or LogFormatJSON. Format is ignored when
+	// NewWriter is set.
+	Format string
+
+	// Target names an additional destination for log records. The only
+	// supported value is LogTargetSyslog, which forwards every record
+	// written to the local syslog daemon.
+	Target string
+
+	// SyslogFacility is the syslog facility used when Target is
+	// LogTargetSyslog, for example "daemon" (the default) or "local0".
+	SyslogFacility string
+
+	// SyslogTag is the tag used when Target is LogTargetSyslog. If it is
+	// empty, the program name is used.
+	SyslogTag string
+
+	// MaxSize is the size in megabytes at which the log file at Path is
+	// rotated. If it is zero, the log file is never rotated.
+	MaxSize int
+
+	// MaxBackups is the number of rotated log files to keep. If it is
+	// zero, all rotated log files are kept, subject to MaxAge.
+	MaxBackups int
+
+	// MaxAge is how long rotated log files are kept for. If it is zero,
+	// rotated log files are kept regardless of their age.
+	MaxAge time.Duration
+
+	// Compress specifies whether rotated log files are gzipped.
+	Compress bool
+
+	// RateLimitBurst, if non-zero, is the number of identical messages
+	// from a module that are written in each RateLimitInterval. Further
+	// duplicates are suppressed, and a count of them is logged once the
+	// interval has passed.
+	RateLimitBurst int
+
+	// RateLimitInterval is the interval over which RateLimitBurst
+	// applies. It defaults to one minute.
+	RateLimitInterval time.Duration
+
+	// AsyncBufferSize, if non-zero, makes writes to the log file at Path
+	// asynchronous, queueing up to this many records so that a slow disk
+	// does not hold up the command. Records logged while the queue is
+	// full are dropped, and a count of them is written later. Call Flush
+	// before exiting to make sure the queued records are written.
+	AsyncBufferSize int
+
+	// Secrets holds sensitive values, such as passwords and keys, that
+	// are replaced with RedactedText in every log record before it is
+	// written to any destination.
+	Secrets []string
+
 	// NewWriter creates a new logging writer for a specified target.
 	NewWriter func(target io.Writer) loggo.Writer
+
+	// RingSize, if non-zero, is the number of recent log records kept in
+	// memory at every level, whatever the levels of the other
+	// destinations, so that they can be written out with DumpRing after
+	// something has gone wrong. Modules given a level in Config are
+	// still logged at that level.
+	RingSize int
+
+	async  *AsyncWriter
+	file   io.Closer
+	syslog io.Closer
+	ring   *RingWriter
+
+	// configPath and rootLevel are kept by Start for ReloadConfig.
+	configPath string
+	rootLevel  loggo.Level
 }
 
+const (
+	// LogTimeRFC3339 writes timestamps as RFC3339 with millisecond
+	// precision, including the time zone offset.
+	LogTimeRFC3339 = "rfc3339"
+
+	// LogTimeEpochMillis writes timestamps as the number of milliseconds
+	// since the Unix epoch.
+	LogTimeEpochMillis = "epoch-millis"
+
+	// LogFormatText writes log records as human readable lines.
+	LogFormatText = "text"
+
+	// LogFormatJSON writes each log record as a single line JSON object.
+	LogFormatJSON = "json"
+)
+
 // GetLogWriter returns a logging writer for the specified target.
 func (l *Log) GetLogWriter(target io.Writer) loggo.Writer {
 	if l.NewWriter != nil {
 		return l.NewWriter(target)
 	}
-	return loggocolor.NewWriter(target)
+	if l.Format == LogFormatJSON {
+		return &jsonWriter{writer: target, tag: l.Tag}
+	}
+	writer := &textWriter{
+		writer:     newColorWriter(target),
+		formatTime: l.formatTime,
+	}
+	return NewTagWriter(writer, l.Tag)
+}
+
+// formatTime formats a log record timestamp as specified by TimeFormat
+// and UTC.
+func (l *Log) formatTime(ts time.Time) string {
+	if l.UTC {
+		ts = ts.UTC()
+	}
+	switch l.TimeFormat {
+	case "":
+		return ts.Format(loggo.TimeFormat)
+	case LogTimeRFC3339:
+		return ts.Format("2006-01-02T15:04:05.000Z07:00")
+	case LogTimeEpochMillis:
+		return strconv.FormatInt(ts.UnixNano()/int64(time.Millisecond), 10)
+	default:
+		return ts.Format(l.TimeFormat)
+	}
 }
 
 // AddFlags adds appropriate flags to f.
 func (l *Log) AddFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&l.Path, "log-file", "", "path to write log to")
-	f.BoolVar(&l.Verbose, "v", false, "show more verbose output")
-	f.BoolVar(&l.Verbose, "verbose", false, "show more verbose output")
+	l.Verbose, l.Verbosity = false, 0
+	verbosity := &verbosityValue{l}
+	f.Var(verbosity, "v", "show more verbose output, repeat for the log at DEBUG (-vv) or TRACE (-vvv)")
+	f.Var(verbosity, "verbose", "show more verbose output, repeat for the log at DEBUG (-vv) or TRACE (-vvv)")
 	f.BoolVar(&l.Quiet, "q", false, "show no informational output")
 	f.BoolVar(&l.Quiet, "quiet", false, "show no informational output")
 	f.BoolVar(&l.Debug, "debug", false, "equivalent to --show-log --logging-config=<root>=DEBUG")
 	f.StringVar(&l.Config, "logging-config", l.DefaultConfig, "specify log levels for modules")
 	f.BoolVar(&l.ShowLog, "show-log", false, "if set, write the log file to stderr")
+	if !l.ExtendedFlags {
+		return
+	}
+	f.BoolVar(&l.Trace, "trace", false, "like --debug, but log every module at TRACE, ignoring --logging-config")
+	f.StringVar(&l.ConfigFile, "logging-config-file", "", "path to a file of module=level lines specifying log levels")
+	f.Var(&levelValue{&l.FileLevel}, "log-file-level", "minimum level of records written to the log file")
+	f.Var(&levelValue{&l.StderrLevel}, "show-log-level", "minimum level of records written to stderr by --show-log")
+	f.StringVar(&l.Format, "log-format", LogFormatText, "format of log records (text|json)")
+	f.StringVar(&l.TimeFormat, "log-time-format", "", "format of log record timestamps (rfc3339|epoch-millis|<Go time layout>)")
+	f.BoolVar(&l.UTC, "log-utc", false, "write log record timestamps in UTC")
+	f.StringVar(&l.Target, "log-target", "", "additional destination for log records (syslog)")
 }
 
 // Start starts logging using the given Context.
@@ -56,45 +242,127 @@ func (log *Log) Start(ctx *Context) error {
 	if log.Verbose && log.Quiet {
 		return fmt.Errorf(`"verbose" and "quiet" flags clash, please use one or the other, not both`)
 	}
-	ctx.quiet = log.Quiet
-	ctx.verbose = log.Verbose
-	if log.Path != "" {
-		path := ctx.AbsPath(log.Path)
-		target, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
-		if err != nil {
-			return err
-		}
-		writer := log.GetLogWriter(target)
-		err = loggo.RegisterWriter("logfile", writer)
-		if err != nil {
+	switch log.Format {
+	case "", LogFormatText, LogFormatJSON:
+	default:
+		return fmt.Errorf("unknown log format %q, expected %q or %q", log.Format, LogFormatText, LogFormatJSON)
+	}
+	if log.Target != "" && log.Target != LogTargetSyslog {
+		return fmt.Errorf("unknown log target %q, expected %q", log.Target, LogTargetSyslog)
+	}
+	config, err := loggo.ParseConfigString(log.Config)
+	if err != nil {
+		return fmt.Errorf("invalid logging config %q: %v", log.Config, err)
+	}
+	var fileConfig string
+	if log.ConfigFile != "" {
+		log.configPath = ctx.AbsPath(log.ConfigFile)
+		if fileConfig, err = readLoggingConfigFile(log.configPath); err != nil {
 			return err
 		}
 	}
+	ctx.quiet = log.Quiet
+	ctx.verbose = log.Verbose
 	level := loggo.WARNING
 	if log.ShowLog {
 		level = loggo.INFO
 	}
+	if log.Verbosity >= 2 || log.Trace {
+		log.Debug = true
+	}
 	if log.Debug {
 		log.ShowLog = true
 		level = loggo.DEBUG
+		if log.Verbosity >= 3 || log.Trace {
+			level = loggo.TRACE
+		}
 		// override quiet or verbose if set, this way all the information goes
 		// to the log file.
 		ctx.quiet = true
 		ctx.verbose = false
 	}
+	// Lower the root level if a destination asks for more than would
+	// otherwise be logged, and make sure the other destinations don't
+	// see those records unless they asked for them too.
+	rootLevel := level
+	if log.RingSize > 0 {
+		rootLevel = loggo.TRACE
+	}
+	if log.Path != "" && log.FileLevel != loggo.UNSPECIFIED && log.FileLevel < rootLevel {
+		rootLevel = log.FileLevel
+	}
+	if log.ShowLog && log.StderrLevel != loggo.UNSPECIFIED && log.StderrLevel < rootLevel {
+		rootLevel = log.StderrLevel
+	}
+	fileLevel := log.FileLevel
+	if fileLevel == loggo.UNSPECIFIED && rootLevel != level {
+		fileLevel = level
+	}
+	stderrLevel := log.StderrLevel
+	if stderrLevel == loggo.UNSPECIFIED && rootLevel != level {
+		stderrLevel = level
+	}
 
+	// Starting again replaces the log file and syslog writers, so let
+	// go of the old ones.
+	log.closeLogFile()
+	if log.Path != "" {
+		target, err := log.openLogFile(ctx.AbsPath(log.Path))
+		if err != nil {
+			return err
+		}
+		log.file = target
+		writer := log.decorate(log.GetLogWriter(target))
+		if fileLevel != loggo.UNSPECIFIED {
+			writer = loggo.NewMinimumLevelWriter(writer, fileLevel)
+		}
+		if log.AsyncBufferSize > 0 {
+			log.async = NewAsyncWriter(writer, log.AsyncBufferSize)
+			writer = log.async
+		}
+		err = loggo.RegisterWriter("logfile", writer)
+		if err != nil {
+			return err
+		}
+	}
+	if log.Target == LogTargetSyslog {
+		syslog, err := connectSyslog(log.SyslogFacility, log.SyslogTag)
+		if err != nil {
+			return err
+		}
+		writer := log.decorate(NewTagWriter(syslog, log.Tag))
+		if rootLevel != level {
+			writer = loggo.NewMinimumLevelWriter(writer, level)
+		}
+		if err := loggo.RegisterWriter("syslog", writer); err != nil {
+			syslog.Close()
+			return err
+		}
+		log.syslog = syslog
+	}
+	if log.RingSize > 0 {
+		log.ring = NewRingWriter(log.RingSize)
+		if err := loggo.RegisterWriter("ring", NewRedactingWriter(log.ring, log.Secrets)); err != nil {
+			return err
+		}
+	}
 	if log.ShowLog {
 		// We replace the default writer to use ctx.Stderr rather than os.Stderr.
-		writer := log.GetLogWriter(ctx.Stderr)
+		writer := log.decorate(log.GetLogWriter(ctx.Stderr))
+		if stderrLevel != loggo.UNSPECIFIED {
+			writer = loggo.NewMinimumLevelWriter(writer, stderrLevel)
+		}
 		_, err := loggo.ReplaceDefaultWriter(writer)
 		if err != nil {
 			return err
 		}
 	} else {
 		loggo.RemoveWriter("default")
+		// Starting again replaces the writer registered last time.
+		loggo.RemoveWriter("warning")
 		// Create a simple writer that doesn't show filenames, or timestamps,
 		// and only shows warning or above.
-		writer := NewWarningWriter(ctx.Stderr)
+		writer := log.decorate(NewWarningWriter(ctx.Stderr))
 		err := loggo.RegisterWriter("warning", writer)
 		if err != nil {
 			return err
@@ -102,12 +370,259 @@ func (log *Log) Start(ctx *Context) error {
 	}
 	// Set the level on the root logger.
 	root := loggo.GetLogger("")
-	root.SetLogLevel(level)
-	// Override the logging config with specified logging config.
-	loggo.ConfigureLoggers(log.Config)
+	root.SetLogLevel(rootLevel)
+	log.rootLevel = rootLevel
+	if log.Trace {
+		// Every module inherits TRACE from the root.
+		loggo.DefaultContext().ResetLoggerLevels()
+		root.SetLogLevel(loggo.TRACE)
+		return nil
+	}
+	// Configuring a module creates it, so look for the unknown ones first.
+	unknown, known := unknownLogModules(config)
+	// Override the logging config with the config file, and then with the
+	// specified logging config.
+	configureLoggers(fileConfig, log.Config)
+	for _, name := range unknown {
+		if len(known) == 0 {
+			logger.Warningf("logging config names unknown module %q", name)
+			continue
+		}
+		logger.Warningf("logging config names unknown module %q, known modules are under %s", name, strings.Join(known, ", "))
+	}
 	return nil
 }
 
+var (
+	configuredModulesMu sync.Mutex
+	// configuredModules holds the modules that were created by applying
+	// a logging config rather than by the code that logs to them.
+	configuredModules = make(map[string]bool)
+)
+
+// configureLoggers applies the logging config specifications in turn,
+// noting the modules that only exist because they were named.
+func configureLoggers(specs ...string) {
+	before := loggo.DefaultContext().CompleteConfig()
+	for _, spec := range specs {
+		loggo.ConfigureLoggers(spec)
+	}
+	configuredModulesMu.Lock()
+	defer configuredModulesMu.Unlock()
+	for name := range loggo.DefaultContext().CompleteConfig() {
+		if _, found := before[name]; !found {
+			configuredModules[name] = true
+		}
+	}
+}
+
+// unknownLogModules returns the modules named in config that neither
+// exist nor have any submodules that do, along with the top level
+// names of the modules that do exist. Modules that were only created by
+// an earlier logging config don't count as existing, so that a mistake
+// is reported every time.
+func unknownLogModules(config loggo.Config) (unknown, known []string) {
+	existing := loggo.DefaultContext().CompleteConfig()
+	configuredModulesMu.Lock()
+	for name := range configuredModules {
+		delete(existing, name)
+	}
+	configuredModulesMu.Unlock()
+	seen := make(map[string]bool)
+	for name := range existing {
+		top := strings.SplitN(name, ".", 2)[0]
+		if top != "" && !seen[top] {
+			seen[top] = true
+			known = append(known, top)
+		}
+	}
+	sort.Strings(known)
+	for name := range config {
+		if name == "" {
+			continue
+		}
+		found := false
+		for module := range existing {
+			if module == name || strings.HasPrefix(module, name+".") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown, known
+}
+
+// levelValue implements gnuflag.Value for a loggo.Level.
+type levelValue struct {
+	level *loggo.Level
+}
+
+// Set implements gnuflag.Value.
+func (v *levelValue) Set(s string) error {
+	level, ok := loggo.ParseLevel(s)
+	if !ok {
+		return fmt.Errorf("unknown severity level %q", s)
+	}
+	*v.level = level
+	return nil
+}
+
+// String implements gnuflag.Value.
+func (v *levelValue) String() string {
+	if *v.level == loggo.UNSPECIFIED {
+		return ""
+	}
+	return v.level.String()
+}
+
+// verbosityValue is the gnuflag.Value for the -v and --verbose flags,
+// counting how many times they are given.
+type verbosityValue struct {
+	log *Log
+}
+
+// Set implements gnuflag.Value.
+func (v *verbosityValue) Set(s string) error {
+	verbose, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if verbose {
+		v.log.Verbosity++
+	} else {
+		v.log.Verbosity = 0
+	}
+	v.log.Verbose = verbose
+	return nil
+}
+
+// String implements gnuflag.Value.
+func (v *verbosityValue) String() string {
+	return strconv.FormatBool(v.log.Verbose)
+}
+
+// IsBoolFlag means the flags can be given without a value.
+func (v *verbosityValue) IsBoolFlag() bool {
+	return true
+}
+
+// readLoggingConfigFile reads the module=level lines from the file at
+// path, and returns them as a logging config specification.
+func readLoggingConfigFile(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read logging config file: %v", err)
+	}
+	var specs []string
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := loggo.ParseConfigString(line); err != nil {
+			return "", fmt.Errorf("line %d bad in logging config file %q: %v", i+1, path, err)
+		}
+		specs = append(specs, line)
+	}
+	return strings.Join(specs, ";"), nil
+}
+
+// ReloadConfig reads ConfigFile again and applies the levels given there
+// and in Config as Start does, so that the levels of a long running
+// process can be changed without restarting it, for instance when it is
+// sent SIGHUP. Modules no longer mentioned go back to the root level. If
+// the file can't be read, the levels are left as they were.
+func (log *Log) ReloadConfig() error {
+	if log.rootLevel == loggo.UNSPECIFIED {
+		return fmt.Errorf("logging has not been started")
+	}
+	if log.Trace {
+		return nil
+	}
+	var fileConfig string
+	if log.configPath != "" {
+		var err error
+		if fileConfig, err = readLoggingConfigFile(log.configPath); err != nil {
+			return err
+		}
+	}
+	loggo.DefaultContext().ResetLoggerLevels()
+	loggo.GetLogger("").SetLogLevel(log.rootLevel)
+	configureLoggers(fileConfig, log.Config)
+	return nil
+}
+
+// Flush waits for any queued log records to be written. It does
+// nothing unless AsyncBufferSize is set.
+func (log *Log) Flush() {
+	if log.async != nil {
+		log.async.Flush()
+	}
+}
+
+// closeLogFile removes the writers and closes the log file and syslog
+// connection set up by a previous call to Start, after writing any
+// queued log records. The writers are removed first so that nothing is
+// written to them once they are closed.
+func (log *Log) closeLogFile() {
+	if log.syslog != nil {
+		loggo.RemoveWriter("syslog")
+		log.syslog.Close()
+		log.syslog = nil
+	}
+	if log.file != nil {
+		loggo.RemoveWriter("logfile")
+	}
+	if log.async != nil {
+		log.async.Close()
+		log.async = nil
+	}
+	if log.file != nil {
+		log.file.Close()
+		log.file = nil
+	}
+}
+
+// DumpRing writes the records kept because of RingSize to w, oldest
+// first, in the configured format.
+func (log *Log) DumpRing(w io.Writer) error {
+	if log.ring == nil {
+		return fmt.Errorf("log records are not being kept in memory")
+	}
+	writer := log.GetLogWriter(w)
+	for _, entry := range log.ring.Entries() {
+		writer.Write(entry)
+	}
+	return nil
+}
+
+// decorate wraps a writer for one of the log destinations with the
+// processing configured for all destinations.
+func (log *Log) decorate(writer loggo.Writer) loggo.Writer {
+	if log.RateLimitBurst > 0 {
+		interval := log.RateLimitInterval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		writer = NewRateLimitWriter(writer, log.RateLimitBurst, interval)
+	}
+	// Redact first, so that secrets are never seen by the later stages.
+	return NewRedactingWriter(writer, log.Secrets)
+}
+
+// openLogFile opens the log file at path for appending, rotating it as
+// it grows if a maximum size has been configured.
+func (log *Log) openLogFile(path string) (io.WriteCloser, error) {
+	if log.MaxSize > 0 {
+		return openRotatingFile(path, int64(log.MaxSize)*megabyte, log.MaxBackups, log.MaxAge, log.Compress)
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
+
 // NewCommandLogWriter creates a loggo writer for registration
 // by the callers of a command. This way the logged output can also
 // be displayed otherwise, e.g. on the screen.
@@ -140,7 +655,7 @@ type warningWriter struct {
 // NewWarningWriter will write out colored severity levels if the writer is
 // outputting to a terminal.
 func NewWarningWriter(writer io.Writer) loggo.Writer {
-	w := &warningWriter{ansiterm.NewWriter(writer)}
+	w := &warningWriter{newColorWriter(writer)}
 	return loggo.NewMinimumLevelWriter(w, loggo.WARNING)
 }
 
@@ -150,3 +665,89 @@ func (w *warningWriter) Write(entry loggo.Entry) {
 	loggocolor.SeverityColor[entry.Level].Fprintf(w.writer, entry.Level.String())
 	fmt.Fprintf(w.writer, " %s\n", entry.Message)
 }
+
+// jsonEntry is the representation of a loggo.Entry written by the JSON
+// writer. The field names are part of the output format, so they should
+// not be changed.
+type jsonEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Module    string    `json:"module"`
+	Tag       string    `json:"tag,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	Message   string    `json:"message"`
+}
+
+type jsonWriter struct {
+	writer io.Writer
+	tag    string
+}
+
+// NewJSONWriter returns a writer that writes each log entry as a single
+// line JSON object, suitable for ingestion by log collectors.
+func NewJSONWriter(writer io.Writer) loggo.Writer {
+	return &jsonWriter{writer: writer}
+}
+
+// Write implements Writer.
+func (w *jsonWriter) Write(entry loggo.Entry) {
+	record := jsonEntry{
+		Timestamp: entry.Timestamp.UTC(),
+		Level:     entry.Level.String(),
+		Module:    entry.Module,
+		Tag:       w.tag,
+		Message:   entry.Message,
+	}
+	if entry.Filename != "" {
+		record.Location = fmt.Sprintf("%s:%d", filepath.Base(entry.Filename), entry.Line)
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		// Writers have nowhere to report errors, so fall back to
+		// writing the message itself rather than dropping it.
+		fmt.Fprintf(w.writer, "%s\n", entry.Message)
+		return
+	}
+	w.writer.Write(append(line, '\n'))
+}
+
+// textWriter writes entries in the same layout as the loggocolor writer,
+// using its colors if the target supports them, but with configurable
+// timestamps.
+type textWriter struct {
+	writer     *ansiterm.Writer
+	formatTime func(time.Time) string
+}
+
+// Write implements loggo.Writer.
+func (w *textWriter) Write(entry loggo.Entry) {
+	// Just get the basename from the filename
+	filename := filepath.Base(entry.Filename)
+
+	fmt.Fprintf(w.writer, "%s ", w.formatTime(entry.Timestamp))
+	loggocolor.SeverityColor[entry.Level].Fprintf(w.writer, entry.Level.Short())
+	fmt.Fprintf(w.writer, " %s ", entry.Module)
+	loggocolor.LocationColor.Fprintf(w.writer, "%s:%d ", filename, entry.Line)
+	fmt.Fprintln(w.writer, entry.Message)
+}
+
+type tagWriter struct {
+	writer loggo.Writer
+	tag    string
+}
+
+// NewTagWriter returns a writer that prefixes every message with the
+// given tag before passing it on to writer. If tag is empty, writer is
+// returned unchanged.
+func NewTagWriter(writer loggo.Writer, tag string) loggo.Writer {
+	if tag == "" {
+		return writer
+	}
+	return &tagWriter{writer: writer, tag: tag}
+}
+
+// Write implements loggo.Writer.
+func (w *tagWriter) Write(entry loggo.Entry) {
+	entry.Message = w.tag + ": " + entry.Message
+	w.writer.Write(entry)
+}
@@ -4,6 +4,8 @@
 package cmd_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"path/filepath"
 
@@ -26,6 +28,7 @@ var _ = gc.Suite(&LogSuite{})
 func newLogWithFlags(c *gc.C, defaultConfig string, flags ...string) *cmd.Log {
 	log := &cmd.Log{
 		DefaultConfig: defaultConfig,
+		ExtendedFlags: true,
 	}
 	flagSet := cmdtesting.NewFlagSet()
 	log.AddFlags(flagSet)
@@ -41,15 +44,30 @@ func (s *LogSuite) TestNoFlags(c *gc.C) {
 	c.Assert(log.Verbose, gc.Equals, false)
 	c.Assert(log.Debug, gc.Equals, false)
 	c.Assert(log.Config, gc.Equals, "")
+	c.Assert(log.Format, gc.Equals, cmd.LogFormatText)
 }
 
 func (s *LogSuite) TestFlags(c *gc.C) {
 	log := newLogWithFlags(c, "", "--log-file", "foo", "--verbose", "--debug",
-		"--logging-config=juju.cmd=INFO;juju.worker.deployer=DEBUG")
+		"--logging-config=juju.cmd=INFO;juju.worker.deployer=DEBUG", "--log-format", "json")
 	c.Assert(log.Path, gc.Equals, "foo")
 	c.Assert(log.Verbose, gc.Equals, true)
 	c.Assert(log.Debug, gc.Equals, true)
 	c.Assert(log.Config, gc.Equals, "juju.cmd=INFO;juju.worker.deployer=DEBUG")
+	c.Assert(log.Format, gc.Equals, cmd.LogFormatJSON)
+}
+
+func (s *LogSuite) TestExtendedFlagsOptIn(c *gc.C) {
+	log := &cmd.Log{}
+	flagSet := cmdtesting.NewFlagSet()
+	log.AddFlags(flagSet)
+	for _, name := range []string{
+		"trace", "logging-config-file", "log-file-level", "show-log-level",
+		"log-format", "log-time-format", "log-utc", "log-target",
+	} {
+		c.Check(flagSet.Lookup(name), gc.IsNil, gc.Commentf("flag %q", name))
+	}
+	c.Assert(flagSet.Lookup("log-file"), gc.NotNil)
 }
 
 func (s *LogSuite) TestLogConfigFromDefault(c *gc.C) {
@@ -73,6 +91,45 @@ func (s *LogSuite) TestDebugSetsLogLevel(c *gc.C) {
 	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "")
 }
 
+func (s *LogSuite) TestVerbosityFlags(c *gc.C) {
+	for _, test := range []struct {
+		args      []string
+		verbosity int
+	}{
+		{nil, 0},
+		{[]string{"-v"}, 1},
+		{[]string{"--verbose"}, 1},
+		{[]string{"-vv", "arg"}, 2},
+		{[]string{"-v", "--verbose"}, 2},
+		{[]string{"-vvv", "arg"}, 3},
+		{[]string{"-vv", "--verbose=false"}, 0},
+	} {
+		c.Logf("args %q", test.args)
+		log := newLogWithFlags(c, "", test.args...)
+		c.Check(log.Verbosity, gc.Equals, test.verbosity)
+		c.Check(log.Verbose, gc.Equals, test.verbosity > 0)
+	}
+}
+
+func (s *LogSuite) TestVerbosityTwiceSetsDebug(c *gc.C) {
+	l := &cmd.Log{Verbose: true, Verbosity: 2}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(l.Debug, gc.Equals, true)
+	c.Assert(loggo.GetLogger("").LogLevel(), gc.Equals, loggo.DEBUG)
+}
+
+func (s *LogSuite) TestVerbosityThreeTimesSetsTrace(c *gc.C) {
+	l := &cmd.Log{Verbose: true, Verbosity: 3}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(loggo.GetLogger("").LogLevel(), gc.Equals, loggo.TRACE)
+}
+
 func (s *LogSuite) TestShowLogSetsLogLevel(c *gc.C) {
 	l := &cmd.Log{ShowLog: true}
 	ctx := cmdtesting.Context(c)
@@ -240,3 +297,284 @@ func (s *LogSuite) TestOutputWarning(c *gc.C) {
 
 	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `^.* WARN .* Writing warning output\n.*`)
 }
+
+func (s *LogSuite) TestJSONFormatLog(c *gc.C) {
+	l := &cmd.Log{Path: "foo.log", Config: "<root>=INFO", Format: cmd.LogFormatJSON}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("hello")
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "foo.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(bytes.Count(content, []byte("\n")), gc.Equals, 1)
+
+	var record map[string]interface{}
+	err = json.Unmarshal(content, &record)
+	c.Assert(err, gc.IsNil)
+	c.Assert(record["level"], gc.Equals, "INFO")
+	c.Assert(record["module"], gc.Equals, "juju.test")
+	c.Assert(record["message"], gc.Equals, "hello")
+	c.Assert(record["location"], gc.Matches, `logging_test.go:\d+`)
+	c.Assert(record["timestamp"], gc.Matches, `\d{4}-\d{2}-\d{2}T.*Z`)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+func (s *LogSuite) TestJSONFormatStderr(c *gc.C) {
+	l := &cmd.Log{ShowLog: true, Config: "<root>=INFO", Format: cmd.LogFormatJSON}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("hello")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `^\{"timestamp":.*"message":"hello"\}\n$`)
+}
+
+func (s *LogSuite) TestUnknownFormat(c *gc.C) {
+	l := &cmd.Log{Format: "xml"}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.ErrorMatches, `unknown log format "xml", expected "text" or "json"`)
+}
+
+func (s *LogSuite) TestConfigFile(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	err := ioutil.WriteFile(filepath.Join(ctx.Dir, "logging.conf"), []byte(`
+# Everything at info, except the noisy bits.
+<root>=INFO
+
+juju.test=TRACE
+juju.cmd=ERROR
+`), 0644)
+	c.Assert(err, gc.IsNil)
+	l := &cmd.Log{ConfigFile: "logging.conf", Config: "juju.cmd=DEBUG"}
+	err = l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(loggo.GetLogger("").LogLevel(), gc.Equals, loggo.INFO)
+	c.Assert(loggo.GetLogger("juju.test").LogLevel(), gc.Equals, loggo.TRACE)
+	// The logging-config flag takes precedence over the file.
+	c.Assert(loggo.GetLogger("juju.cmd").LogLevel(), gc.Equals, loggo.DEBUG)
+}
+
+func (s *LogSuite) TestReloadConfig(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	path := filepath.Join(ctx.Dir, "logging.conf")
+	err := ioutil.WriteFile(path, []byte("juju.test=TRACE\njuju.worker=ERROR\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	l := &cmd.Log{ConfigFile: "logging.conf", Config: "juju.cmd=DEBUG"}
+	err = l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	c.Assert(loggo.GetLogger("juju.worker").LogLevel(), gc.Equals, loggo.ERROR)
+
+	err = ioutil.WriteFile(path, []byte("juju.test=INFO\njuju.cmd=ERROR\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	err = l.ReloadConfig()
+	c.Assert(err, gc.IsNil)
+	c.Assert(loggo.GetLogger("").LogLevel(), gc.Equals, loggo.WARNING)
+	c.Assert(loggo.GetLogger("juju.test").LogLevel(), gc.Equals, loggo.INFO)
+	c.Assert(loggo.GetLogger("juju.worker").LogLevel(), gc.Equals, loggo.UNSPECIFIED)
+	// Config still takes precedence over the file.
+	c.Assert(loggo.GetLogger("juju.cmd").LogLevel(), gc.Equals, loggo.DEBUG)
+
+	// A bad file leaves the levels alone.
+	err = ioutil.WriteFile(path, []byte("juju.test=LOUD\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	err = l.ReloadConfig()
+	c.Assert(err, gc.ErrorMatches, `line 1 bad in logging config file .*`)
+	c.Assert(loggo.GetLogger("juju.test").LogLevel(), gc.Equals, loggo.INFO)
+}
+
+func (s *LogSuite) TestReloadConfigNotStarted(c *gc.C) {
+	l := &cmd.Log{}
+	c.Assert(l.ReloadConfig(), gc.ErrorMatches, "logging has not been started")
+}
+
+func (s *LogSuite) TestConfigFileFlag(c *gc.C) {
+	log := newLogWithFlags(c, "", "--logging-config-file", "logging.conf")
+	c.Assert(log.ConfigFile, gc.Equals, "logging.conf")
+}
+
+func (s *LogSuite) TestConfigFileMissing(c *gc.C) {
+	l := &cmd.Log{ConfigFile: "missing.conf"}
+	err := l.Start(cmdtesting.Context(c))
+	c.Assert(err, gc.ErrorMatches, "cannot read logging config file: .*")
+}
+
+func (s *LogSuite) TestConfigFileBadLine(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	path := filepath.Join(ctx.Dir, "logging.conf")
+	err := ioutil.WriteFile(path, []byte("<root>=INFO\njuju.test=LOUD\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	l := &cmd.Log{ConfigFile: path}
+	err = l.Start(ctx)
+	c.Assert(err, gc.ErrorMatches, `line 2 bad in logging config file ".*logging.conf": unknown severity level "LOUD"`)
+}
+
+func (s *LogSuite) TestConfigInvalid(c *gc.C) {
+	l := &cmd.Log{Config: "juju.test=DEBUG;juju.cmd=LOUD"}
+	err := l.Start(cmdtesting.Context(c))
+	c.Assert(err, gc.ErrorMatches, `invalid logging config "juju.test=DEBUG;juju.cmd=LOUD": unknown severity level "LOUD"`)
+	// Nothing was configured.
+	c.Assert(loggo.GetLogger("juju.test").LogLevel(), gc.Equals, loggo.UNSPECIFIED)
+}
+
+func (s *LogSuite) TestConfigUnknownModule(c *gc.C) {
+	// Other tests create loggers of their own, and configuring the
+	// misspelt module creates it, so starting again must still report it.
+	loggo.GetLogger("cmdtest.worker.uniter")
+	l := &cmd.Log{Config: "cmdtest.worker=DEBUG;cmdtest.wroker=DEBUG"}
+	for i := 0; i < 2; i++ {
+		loggo.ResetWriters()
+		ctx := cmdtesting.Context(c)
+		err := l.Start(ctx)
+		c.Assert(err, gc.IsNil)
+		c.Assert(cmdtesting.Stderr(ctx), gc.Matches,
+			`WARNING logging config names unknown module "cmdtest.wroker", known modules are under (.*, )?cmdtest(, .*)?\n`)
+		c.Assert(loggo.GetLogger("cmdtest.worker.uniter").EffectiveLogLevel(), gc.Equals, loggo.DEBUG)
+	}
+}
+
+func (s *LogSuite) TestTraceFlag(c *gc.C) {
+	log := newLogWithFlags(c, "", "--trace")
+	c.Assert(log.Trace, gc.Equals, true)
+}
+
+func (s *LogSuite) TestTraceOverridesConfig(c *gc.C) {
+	loggo.GetLogger("juju.worker").SetLogLevel(loggo.ERROR)
+	l := &cmd.Log{Trace: true, Config: "<root>=WARNING;juju.cmd=INFO"}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(l.Debug, gc.Equals, true)
+	c.Assert(l.ShowLog, gc.Equals, true)
+	c.Assert(loggo.GetLogger("").LogLevel(), gc.Equals, loggo.TRACE)
+	c.Assert(loggo.GetLogger("juju.worker").EffectiveLogLevel(), gc.Equals, loggo.TRACE)
+	c.Assert(loggo.GetLogger("juju.cmd").EffectiveLogLevel(), gc.Equals, loggo.TRACE)
+}
+
+func (s *LogSuite) TestLevelFlags(c *gc.C) {
+	log := newLogWithFlags(c, "", "--log-file-level", "debug", "--show-log-level", "ERROR")
+	c.Assert(log.FileLevel, gc.Equals, loggo.DEBUG)
+	c.Assert(log.StderrLevel, gc.Equals, loggo.ERROR)
+}
+
+func (s *LogSuite) TestLevelFlagsInvalid(c *gc.C) {
+	log := &cmd.Log{ExtendedFlags: true}
+	flagSet := cmdtesting.NewFlagSet()
+	log.AddFlags(flagSet)
+	err := flagSet.Parse(false, []string{"--log-file-level", "loud"})
+	c.Assert(err, gc.ErrorMatches, `invalid value "loud" for flag --log-file-level: unknown severity level "loud"`)
+}
+
+func (s *LogSuite) TestFileLevelLowerThanStderr(c *gc.C) {
+	l := &cmd.Log{Path: "foo.log", ShowLog: true, FileLevel: loggo.DEBUG}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	c.Assert(loggo.GetLogger("").LogLevel(), gc.Equals, loggo.DEBUG)
+
+	logger.Debugf("debug detail")
+	logger.Infof("hello")
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "foo.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* DEBUG .* debug detail\n.* INFO .* hello\n`)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `^.* INFO .* hello\n`)
+}
+
+func (s *LogSuite) TestStderrLevelHigherThanFile(c *gc.C) {
+	l := &cmd.Log{Path: "foo.log", ShowLog: true, Config: "<root>=INFO", StderrLevel: loggo.WARNING}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+
+	logger.Infof("hello")
+	logger.Warningf("careful")
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "foo.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* INFO .* hello\n.* WARN .* careful\n`)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `^.* WARN .* careful\n`)
+}
+
+func (s *LogSuite) TestStderrLevelLowerThanFile(c *gc.C) {
+	l := &cmd.Log{Path: "foo.log", ShowLog: true, StderrLevel: loggo.DEBUG}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	c.Assert(loggo.GetLogger("").LogLevel(), gc.Equals, loggo.DEBUG)
+
+	logger.Debugf("debug detail")
+	logger.Infof("hello")
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "foo.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* INFO .* hello\n`)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `^.* DEBUG .* debug detail\n.* INFO .* hello\n`)
+}
+
+func (s *LogSuite) TestNoColor(c *gc.C) {
+	s.PatchEnvironment("NO_COLOR", "1")
+	l := &cmd.Log{ShowLog: true, Config: "<root>=INFO"}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("hello")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `^\d\d:\d\d:\d\d INFO  juju.test logging_test.go:\d+ hello\n$`)
+}
+
+func (s *LogSuite) TestTag(c *gc.C) {
+	l := &cmd.Log{Path: "foo.log", ShowLog: true, Config: "<root>=INFO", Tag: "machine-0"}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("hello")
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "foo.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* INFO +juju.test .* machine-0: hello\n`)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `^.* INFO +juju.test .* machine-0: hello\n`)
+}
+
+func (s *LogSuite) TestTagJSON(c *gc.C) {
+	l := &cmd.Log{ShowLog: true, Config: "<root>=INFO", Format: cmd.LogFormatJSON, Tag: "unit-mysql-3"}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("hello")
+
+	var record map[string]interface{}
+	err = json.Unmarshal([]byte(cmdtesting.Stderr(ctx)), &record)
+	c.Assert(err, gc.IsNil)
+	c.Assert(record["tag"], gc.Equals, "unit-mysql-3")
+	c.Assert(record["message"], gc.Equals, "hello")
+}
+
+func (s *LogSuite) TestTagWriterNoTag(c *gc.C) {
+	target := &loggo.TestWriter{}
+	c.Assert(cmd.NewTagWriter(target, ""), gc.Equals, loggo.Writer(target))
+}
+
+func (s *LogSuite) TestTimeFormatFlags(c *gc.C) {
+	log := newLogWithFlags(c, "", "--log-time-format", "epoch-millis", "--log-utc")
+	c.Assert(log.TimeFormat, gc.Equals, cmd.LogTimeEpochMillis)
+	c.Assert(log.UTC, gc.Equals, true)
+}
+
+func (s *LogSuite) TestTimeFormats(c *gc.C) {
+	for i, test := range []struct {
+		format   string
+		utc      bool
+		expected string
+	}{
+		{"", false, `\d\d:\d\d:\d\d`},
+		{cmd.LogTimeRFC3339, true, `\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}Z`},
+		{cmd.LogTimeRFC3339, false, `\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}(Z|[+-]\d\d:\d\d)`},
+		{cmd.LogTimeEpochMillis, false, `\d{13}`},
+		{"2006/01/02 MST", true, `\d{4}/\d\d/\d\d UTC`},
+	} {
+		c.Logf("test %d: %q", i, test.format)
+		l := &cmd.Log{ShowLog: true, Config: "<root>=INFO", TimeFormat: test.format, UTC: test.utc}
+		ctx := cmdtesting.Context(c)
+		err := l.Start(ctx)
+		c.Assert(err, gc.IsNil)
+		logger.Infof("hello")
+		c.Check(cmdtesting.Stderr(ctx), gc.Matches, "^"+test.expected+` INFO  juju.test logging_test.go:\d+ hello\n$`)
+	}
+}
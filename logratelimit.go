@@ -0,0 +1,120 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/juju/loggo"
+)
+
+// rateLimitKey identifies log entries that are considered duplicates.
+type rateLimitKey struct {
+	module  string
+	level   loggo.Level
+	message string
+}
+
+// maxRateLimitWindows bounds the number of distinct messages tracked at
+// once. When there are more, the oldest window is closed early.
+const maxRateLimitWindows = 1000
+
+// rateLimitWindow tracks how often a message has been written in the
+// interval that started with its first occurrence.
+type rateLimitWindow struct {
+	key        rateLimitKey
+	start      time.Time
+	count      int
+	suppressed int
+	last       loggo.Entry
+}
+
+type rateLimitWriter struct {
+	writer   loggo.Writer
+	burst    int
+	interval time.Duration
+	windows  map[rateLimitKey]*list.Element
+	// order holds the windows in the order they were opened, so those
+	// that have ended are found at the front.
+	order *list.List
+}
+
+// NewRateLimitWriter returns a writer that passes at most burst identical
+// messages from the same module to writer in each interval. Once an
+// interval has passed, a summary of how many duplicates were suppressed
+// is written in their place.
+//
+// The interval is measured using the entry timestamps, so the summary is
+// written along with the first entry logged after the interval ends.
+func NewRateLimitWriter(writer loggo.Writer, burst int, interval time.Duration) loggo.Writer {
+	return &rateLimitWriter{
+		writer:   writer,
+		burst:    burst,
+		interval: interval,
+		windows:  make(map[rateLimitKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Write implements loggo.Writer.
+func (w *rateLimitWriter) Write(entry loggo.Entry) {
+	w.expire(entry.Timestamp)
+
+	key := rateLimitKey{entry.Module, entry.Level, entry.Message}
+	element, found := w.windows[key]
+	if !found {
+		if len(w.windows) >= maxRateLimitWindows {
+			w.close([]*list.Element{w.order.Front()}, entry.Timestamp)
+		}
+		element = w.order.PushBack(&rateLimitWindow{key: key, start: entry.Timestamp})
+		w.windows[key] = element
+	}
+	window := element.Value.(*rateLimitWindow)
+	window.count++
+	if window.count > w.burst {
+		window.suppressed++
+		window.last = entry
+		return
+	}
+	w.writer.Write(entry)
+}
+
+// expire closes the windows that ended before now.
+func (w *rateLimitWriter) expire(now time.Time) {
+	var ended []*list.Element
+	for element := w.order.Front(); element != nil; element = element.Next() {
+		if now.Sub(element.Value.(*rateLimitWindow).start) < w.interval {
+			break
+		}
+		ended = append(ended, element)
+	}
+	w.close(ended, now)
+}
+
+// close stops tracking the given windows, writing a summary for any that
+// suppressed messages.
+func (w *rateLimitWriter) close(elements []*list.Element, now time.Time) {
+	var summaries []loggo.Entry
+	for _, element := range elements {
+		window := w.order.Remove(element).(*rateLimitWindow)
+		delete(w.windows, window.key)
+		if window.suppressed > 0 {
+			summary := window.last
+			summary.Message = fmt.Sprintf("suppressed %d duplicates of: %s", window.suppressed, window.key.message)
+			summaries = append(summaries, summary)
+		}
+	}
+	// Write the summaries in the order the suppressed messages were last
+	// seen, rather than in the order their windows opened.
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Timestamp.Before(summaries[j].Timestamp)
+	})
+	for _, summary := range summaries {
+		summary.Timestamp = now
+		w.writer.Write(summary)
+	}
+}
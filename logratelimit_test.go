@@ -0,0 +1,118 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type RateLimitSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&RateLimitSuite{})
+
+var rateLimitEpoch = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func rateLimitEntry(module, message string, offset time.Duration) loggo.Entry {
+	return loggo.Entry{
+		Level:     loggo.ERROR,
+		Module:    module,
+		Message:   message,
+		Timestamp: rateLimitEpoch.Add(offset),
+	}
+}
+
+func messages(entries []loggo.Entry) []string {
+	var result []string
+	for _, entry := range entries {
+		result = append(result, entry.Module+": "+entry.Message)
+	}
+	return result
+}
+
+func (s *RateLimitSuite) TestSuppressesDuplicates(c *gc.C) {
+	target := &loggo.TestWriter{}
+	w := cmd.NewRateLimitWriter(target, 2, time.Minute)
+	for i := 0; i < 5; i++ {
+		w.Write(rateLimitEntry("juju.worker", "flap", time.Duration(i)*time.Second))
+	}
+	w.Write(rateLimitEntry("juju.worker", "different", 6*time.Second))
+	w.Write(rateLimitEntry("juju.other", "flap", 7*time.Second))
+	c.Assert(messages(target.Log()), gc.DeepEquals, []string{
+		"juju.worker: flap",
+		"juju.worker: flap",
+		"juju.worker: different",
+		"juju.other: flap",
+	})
+}
+
+func (s *RateLimitSuite) TestSummaryAfterInterval(c *gc.C) {
+	target := &loggo.TestWriter{}
+	w := cmd.NewRateLimitWriter(target, 1, time.Minute)
+	for i := 0; i < 4; i++ {
+		w.Write(rateLimitEntry("juju.worker", "flap", time.Duration(i)*time.Second))
+	}
+	w.Write(rateLimitEntry("juju.worker", "flap", 2*time.Minute))
+	log := target.Log()
+	c.Assert(messages(log), gc.DeepEquals, []string{
+		"juju.worker: flap",
+		"juju.worker: suppressed 3 duplicates of: flap",
+		"juju.worker: flap",
+	})
+	c.Assert(log[1].Level, gc.Equals, loggo.ERROR)
+	c.Assert(log[1].Timestamp, gc.Equals, rateLimitEpoch.Add(2*time.Minute))
+}
+
+func (s *RateLimitSuite) TestNoSummaryWithoutSuppression(c *gc.C) {
+	target := &loggo.TestWriter{}
+	w := cmd.NewRateLimitWriter(target, 2, time.Minute)
+	w.Write(rateLimitEntry("juju.worker", "flap", 0))
+	w.Write(rateLimitEntry("juju.worker", "flap", 2*time.Minute))
+	c.Assert(messages(target.Log()), gc.DeepEquals, []string{
+		"juju.worker: flap",
+		"juju.worker: flap",
+	})
+}
+
+func (s *RateLimitSuite) TestLogRateLimit(c *gc.C) {
+	l := &cmd.Log{Config: "<root>=INFO", RateLimitBurst: 1}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Warningf("flap")
+	logger.Warningf("flap")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "WARNING flap\n")
+}
+
+func (s *RateLimitSuite) TestDistinctMessagesAreBounded(c *gc.C) {
+	target := &loggo.TestWriter{}
+	w := cmd.NewRateLimitWriter(target, 1, time.Hour)
+	w.Write(rateLimitEntry("juju.worker", "flap", 0))
+	w.Write(rateLimitEntry("juju.worker", "flap", time.Second))
+	// Enough other messages to push the first out of the writer's
+	// memory, which summarises it early.
+	for i := 0; i < cmd.MaxRateLimitWindows; i++ {
+		w.Write(rateLimitEntry("juju.worker", fmt.Sprintf("unique %d", i), 2*time.Second))
+	}
+	log := messages(target.Log())
+	c.Assert(log, gc.HasLen, cmd.MaxRateLimitWindows+2)
+	c.Assert(log[:3], gc.DeepEquals, []string{
+		"juju.worker: flap",
+		"juju.worker: unique 0",
+		"juju.worker: unique 1",
+	})
+	c.Assert(log[len(log)-2:], gc.DeepEquals, []string{
+		"juju.worker: suppressed 1 duplicates of: flap",
+		fmt.Sprintf("juju.worker: unique %d", cmd.MaxRateLimitWindows-1),
+	})
+}
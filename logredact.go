@@ -0,0 +1,53 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/juju/loggo"
+)
+
+// RedactedText replaces secrets in messages written by a redacting writer.
+const RedactedText = "[REDACTED]"
+
+type redactingWriter struct {
+	writer   loggo.Writer
+	replacer *strings.Replacer
+}
+
+// NewRedactingWriter returns a writer that replaces every occurrence of
+// the given secrets in log messages with RedactedText before passing them
+// on to writer. Empty secrets are ignored.
+func NewRedactingWriter(writer loggo.Writer, secrets []string) loggo.Writer {
+	var sorted []string
+	for _, secret := range secrets {
+		if secret != "" {
+			sorted = append(sorted, secret)
+		}
+	}
+	if len(sorted) == 0 {
+		return writer
+	}
+	// The replacer tries each secret in order, so put the longest first
+	// in case one secret contains another.
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+	pairs := make([]string, 0, 2*len(sorted))
+	for _, secret := range sorted {
+		pairs = append(pairs, secret, RedactedText)
+	}
+	return &redactingWriter{
+		writer:   writer,
+		replacer: strings.NewReplacer(pairs...),
+	}
+}
+
+// Write implements loggo.Writer.
+func (w *redactingWriter) Write(entry loggo.Entry) {
+	entry.Message = w.replacer.Replace(entry.Message)
+	w.writer.Write(entry)
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type RedactSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&RedactSuite{})
+
+func (s *RedactSuite) TestRedactsSecrets(c *gc.C) {
+	target := &loggo.TestWriter{}
+	w := cmd.NewRedactingWriter(target, []string{"hunter2", "", "s3cr3t-token"})
+	w.Write(loggo.Entry{Level: loggo.INFO, Message: "login with hunter2 and s3cr3t-token, hunter2 again"})
+	w.Write(loggo.Entry{Level: loggo.INFO, Message: "nothing to see"})
+	c.Assert(messages(target.Log()), gc.DeepEquals, []string{
+		": login with [REDACTED] and [REDACTED], [REDACTED] again",
+		": nothing to see",
+	})
+}
+
+func (s *RedactSuite) TestLongestSecretFirst(c *gc.C) {
+	target := &loggo.TestWriter{}
+	w := cmd.NewRedactingWriter(target, []string{"pass", "password123"})
+	w.Write(loggo.Entry{Level: loggo.INFO, Message: "password123 pass"})
+	c.Assert(messages(target.Log()), gc.DeepEquals, []string{
+		": [REDACTED] [REDACTED]",
+	})
+}
+
+func (s *RedactSuite) TestNoSecrets(c *gc.C) {
+	target := &loggo.TestWriter{}
+	w := cmd.NewRedactingWriter(target, nil)
+	c.Assert(w, gc.Equals, loggo.Writer(target))
+}
+
+func (s *RedactSuite) TestLogSecrets(c *gc.C) {
+	l := &cmd.Log{Path: "foo.log", Config: "<root>=INFO", Secrets: []string{"hunter2"}}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("password is hunter2")
+	logger.Warningf("still hunter2")
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "foo.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* INFO .* password is \[REDACTED\]\n.* WARN .* still \[REDACTED\]\n`)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "WARNING still [REDACTED]\n")
+}
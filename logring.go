@@ -0,0 +1,54 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"sync"
+
+	"github.com/juju/loggo"
+)
+
+// RingWriter is a loggo.Writer that keeps the most recent entries in
+// memory, discarding the oldest once it is full.
+type RingWriter struct {
+	mu      sync.Mutex
+	entries []loggo.Entry
+	next    int
+	full    bool
+}
+
+// NewRingWriter returns a RingWriter that keeps the last size entries.
+// If size is not positive, nothing is kept.
+func NewRingWriter(size int) *RingWriter {
+	if size < 0 {
+		size = 0
+	}
+	return &RingWriter{entries: make([]loggo.Entry, size)}
+}
+
+// Write implements loggo.Writer.
+func (w *RingWriter) Write(entry loggo.Entry) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.entries) == 0 {
+		return
+	}
+	w.entries[w.next] = entry
+	w.next++
+	if w.next == len(w.entries) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// Entries returns a copy of the entries kept, oldest first.
+func (w *RingWriter) Entries() []loggo.Entry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.full {
+		return append([]loggo.Entry(nil), w.entries[:w.next]...)
+	}
+	result := append([]loggo.Entry(nil), w.entries[w.next:]...)
+	return append(result, w.entries[:w.next]...)
+}
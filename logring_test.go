@@ -0,0 +1,75 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type RingSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&RingSuite{})
+
+func (s *RingSuite) TestEntries(c *gc.C) {
+	w := cmd.NewRingWriter(3)
+	c.Assert(w.Entries(), gc.HasLen, 0)
+	w.Write(rateLimitEntry("juju.worker", "one", 0))
+	w.Write(rateLimitEntry("juju.worker", "two", time.Second))
+	c.Assert(messages(w.Entries()), gc.DeepEquals, []string{
+		"juju.worker: one",
+		"juju.worker: two",
+	})
+}
+
+func (s *RingSuite) TestDiscardsOldest(c *gc.C) {
+	w := cmd.NewRingWriter(3)
+	for i := 0; i < 5; i++ {
+		w.Write(rateLimitEntry("juju.worker", fmt.Sprint(i), time.Duration(i)*time.Second))
+	}
+	c.Assert(messages(w.Entries()), gc.DeepEquals, []string{
+		"juju.worker: 2",
+		"juju.worker: 3",
+		"juju.worker: 4",
+	})
+}
+
+func (s *RingSuite) TestNoSize(c *gc.C) {
+	for _, size := range []int{0, -1} {
+		w := cmd.NewRingWriter(size)
+		w.Write(rateLimitEntry("juju.worker", "dropped", 0))
+		c.Assert(w.Entries(), gc.HasLen, 0)
+	}
+}
+
+func (s *LogSuite) TestDumpRing(c *gc.C) {
+	l := &cmd.Log{RingSize: 10}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Debugf("context")
+	logger.Warningf("problem")
+
+	// Only the warning goes to stderr, but both are kept.
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "WARNING problem\n")
+	var buf bytes.Buffer
+	err = l.DumpRing(&buf)
+	c.Assert(err, gc.IsNil)
+	c.Assert(buf.String(), gc.Matches, `^.* DEBUG .* context\n.* WARN .* problem\n$`)
+}
+
+func (s *LogSuite) TestDumpRingNotKept(c *gc.C) {
+	l := &cmd.Log{}
+	err := l.DumpRing(&bytes.Buffer{})
+	c.Assert(err, gc.ErrorMatches, "log records are not being kept in memory")
+}
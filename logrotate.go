@@ -0,0 +1,265 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const megabyte = 1024 * 1024
+
+// rotatingFile is a log file that is rotated once it reaches a maximum
+// size. Log writers may write a record in several pieces, so the file is
+// only rotated at the start of a line, keeping each record in one file.
+// Rotated files are named after the log file with a generation
+// suffix, the most recent being path.1, and are optionally compressed.
+// Compression happens in the background, as writes to the log hold up
+// all logging in the process.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+	// midLine is whether the last write didn't end with a newline.
+	midLine bool
+	// compressed is set while the last rotated file is compressed,
+	// and receives the result once it has been, and the old files
+	// pruned.
+	compressed chan error
+}
+
+// openRotatingFile opens the log file at path for appending. The file is
+// rotated when a write would take it over maxSize bytes. At most
+// maxBackups rotated files are kept, and those older than maxAge are
+// removed; a zero value for either means no limit.
+func openRotatingFile(path string, maxSize int64, maxBackups int, maxAge time.Duration, compress bool) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		maxAge:     maxAge,
+		compress:   compress,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write implements io.Writer. The data is written even if rotating the
+// file fails, in which case the rotation error is returned.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, fmt.Errorf("log file %q is closed", f.path)
+	}
+	var rotateErr error
+	if f.file != nil && !f.midLine && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		rotateErr = f.rotate()
+	}
+	if f.file == nil {
+		// The file couldn't be reopened after rotating; try again.
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if n > 0 {
+		f.midLine = p[n-1] != '\n'
+	}
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// Close implements io.Closer. It waits for any rotated file to be
+// compressed, and returns the error if that failed.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	err := f.waitCompressed()
+	if f.file != nil {
+		if closeErr := f.file.Close(); closeErr != nil {
+			err = closeErr
+		}
+		f.file = nil
+	}
+	return err
+}
+
+// waitCompressed waits for the last rotated file to be compressed, and
+// returns the error if that failed.
+func (f *rotatingFile) waitCompressed() error {
+	if f.compressed == nil {
+		return nil
+	}
+	err := <-f.compressed
+	f.compressed = nil
+	return err
+}
+
+// backupName returns the name of the given generation of rotated file.
+func (f *rotatingFile) backupName(generation int, compressed bool) string {
+	name := fmt.Sprintf("%s.%d", f.path, generation)
+	if compressed {
+		name += ".gz"
+	}
+	return name
+}
+
+// findBackup returns the name of the given generation of rotated file,
+// compressed or not, or false if there is no such file.
+func (f *rotatingFile) findBackup(generation int) (string, bool, bool) {
+	for _, compressed := range []bool{false, true} {
+		name := f.backupName(generation, compressed)
+		if _, err := os.Stat(name); err == nil {
+			return name, compressed, true
+		}
+	}
+	return "", false, false
+}
+
+// rotate moves the log file aside and opens a new one. The log file is
+// reopened whatever happens, so that logging carries on, to the old file
+// if it couldn't be moved.
+func (f *rotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err == nil {
+		err = f.moveAside()
+	}
+	if openErr := f.open(); openErr != nil {
+		return openErr
+	}
+	return err
+}
+
+// moveAside renames the closed log file to the first generation of
+// backup, moving the older generations up and pruning them. If the
+// last rotated file couldn't be compressed, that error is returned.
+func (f *rotatingFile) moveAside() error {
+	// The backups mustn't be moved while one is being compressed.
+	compressErr := f.waitCompressed()
+	// Shuffle the existing generations up by one, starting with the
+	// oldest, so that path.1 is free for the current file.
+	last := 0
+	for {
+		if _, _, found := f.findBackup(last + 1); !found {
+			break
+		}
+		last++
+	}
+	for generation := last; generation > 0; generation-- {
+		name, compressed, _ := f.findBackup(generation)
+		if err := os.Rename(name, f.backupName(generation+1, compressed)); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(f.path, f.backupName(1, false)); err != nil {
+		return err
+	}
+	if !f.compress {
+		f.prune(last + 1)
+		return compressErr
+	}
+	compressed := make(chan error, 1)
+	f.compressed = compressed
+	go func() {
+		err := compressFile(f.backupName(1, false), f.backupName(1, true))
+		f.prune(last + 1)
+		compressed <- err
+	}()
+	return compressErr
+}
+
+// prune removes the rotated files that are beyond the configured number
+// of generations or older than the configured age.
+func (f *rotatingFile) prune(generations int) {
+	for generation := 1; generation <= generations; generation++ {
+		name, _, found := f.findBackup(generation)
+		if !found {
+			continue
+		}
+		remove := f.maxBackups > 0 && generation > f.maxBackups
+		if !remove && f.maxAge > 0 {
+			if info, err := os.Stat(name); err == nil {
+				remove = time.Since(info.ModTime()) > f.maxAge
+			}
+		}
+		if remove {
+			// This runs inside a loggo writer, or while one waits for
+			// it, so it must not log. A file that can't be removed now
+			// is tried again after the next rotation.
+			os.Remove(name)
+		}
+	}
+}
+
+// compressFile writes a gzipped copy of src to dst and removes src.
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// Keep the modification time so that age based pruning applies to
+	// when the log was written rather than when it was compressed.
+	if err := os.Chtimes(dst, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type RotatingFileSuite struct {
+	testing.IsolationSuite
+	path string
+}
+
+var _ = gc.Suite(&RotatingFileSuite{})
+
+func (s *RotatingFileSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.path = filepath.Join(c.MkDir(), "foo.log")
+}
+
+func (s *RotatingFileSuite) open(c *gc.C, maxBackups int, maxAge time.Duration, compress bool) io.WriteCloser {
+	f, err := cmd.NewRotatingFile(s.path, 10, maxBackups, maxAge, compress)
+	c.Assert(err, gc.IsNil)
+	return f
+}
+
+func (s *RotatingFileSuite) assertContent(c *gc.C, path, expected string) {
+	content, err := ioutil.ReadFile(path)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Equals, expected)
+}
+
+func (s *RotatingFileSuite) TestNoRotationUnderLimit(c *gc.C) {
+	f := s.open(c, 0, 0, false)
+	defer f.Close()
+	fmt.Fprint(f, "12345")
+	fmt.Fprint(f, "6789")
+
+	s.assertContent(c, s.path, "123456789")
+	_, err := os.Stat(s.path + ".1")
+	c.Assert(os.IsNotExist(err), gc.Equals, true)
+}
+
+func (s *RotatingFileSuite) TestRotation(c *gc.C) {
+	f := s.open(c, 0, 0, false)
+	defer f.Close()
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		fmt.Fprint(f, line)
+	}
+
+	s.assertContent(c, s.path, "third\n")
+	s.assertContent(c, s.path+".1", "second\n")
+	s.assertContent(c, s.path+".2", "first\n")
+}
+
+func (s *RotatingFileSuite) TestRotationKeepsLinesWhole(c *gc.C) {
+	f := s.open(c, 0, 0, false)
+	defer f.Close()
+	for _, piece := range []string{"08:42 ", "INFO ", "first\n", "08:43 ", "INFO ", "second\n"} {
+		fmt.Fprint(f, piece)
+	}
+
+	s.assertContent(c, s.path, "08:43 INFO second\n")
+	s.assertContent(c, s.path+".1", "08:42 INFO first\n")
+}
+
+func (s *RotatingFileSuite) TestRotationAppendsToExisting(c *gc.C) {
+	err := ioutil.WriteFile(s.path, []byte("existing\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	f := s.open(c, 0, 0, false)
+	defer f.Close()
+	fmt.Fprint(f, "more\n")
+
+	s.assertContent(c, s.path, "more\n")
+	s.assertContent(c, s.path+".1", "existing\n")
+}
+
+func (s *RotatingFileSuite) TestMaxBackups(c *gc.C) {
+	f := s.open(c, 2, 0, false)
+	defer f.Close()
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		fmt.Fprint(f, line)
+	}
+
+	s.assertContent(c, s.path, "fourth\n")
+	s.assertContent(c, s.path+".1", "third\n")
+	s.assertContent(c, s.path+".2", "second\n")
+	_, err := os.Stat(s.path + ".3")
+	c.Assert(os.IsNotExist(err), gc.Equals, true)
+}
+
+func (s *RotatingFileSuite) TestMaxAge(c *gc.C) {
+	old := s.path + ".1"
+	err := ioutil.WriteFile(old, []byte("old\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	stale := time.Now().Add(-48 * time.Hour)
+	err = os.Chtimes(old, stale, stale)
+	c.Assert(err, gc.IsNil)
+
+	f := s.open(c, 0, 24*time.Hour, false)
+	defer f.Close()
+	fmt.Fprint(f, "first\n")
+	fmt.Fprint(f, "second\n")
+
+	s.assertContent(c, s.path, "second\n")
+	s.assertContent(c, s.path+".1", "first\n")
+	_, err = os.Stat(s.path + ".2")
+	c.Assert(os.IsNotExist(err), gc.Equals, true)
+}
+
+func (s *RotatingFileSuite) TestCompress(c *gc.C) {
+	f := s.open(c, 0, 0, true)
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		fmt.Fprint(f, line)
+	}
+	// The rotated files are compressed in the background.
+	err := f.Close()
+	c.Assert(err, gc.IsNil)
+
+	s.assertContent(c, s.path, "third\n")
+	for i, expected := range []string{"second\n", "first\n"} {
+		name := fmt.Sprintf("%s.%d", s.path, i+1)
+		_, err := os.Stat(name)
+		c.Assert(os.IsNotExist(err), gc.Equals, true)
+
+		in, err := os.Open(name + ".gz")
+		c.Assert(err, gc.IsNil)
+		gz, err := gzip.NewReader(in)
+		c.Assert(err, gc.IsNil)
+		content, err := ioutil.ReadAll(gz)
+		in.Close()
+		c.Assert(err, gc.IsNil)
+		c.Assert(string(content), gc.Equals, expected)
+	}
+}
+
+func (s *LogSuite) TestRotatedLog(c *gc.C) {
+	l := &cmd.Log{Path: "foo.log", Config: "<root>=INFO", MaxSize: 1}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("hello")
+	content, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "foo.log"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* INFO .* hello\n`)
+}
+
+func (s *LogSuite) TestRotatedLogPruneFailureDoesNotLog(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	path := filepath.Join(ctx.Dir, "foo.log")
+	err := ioutil.WriteFile(path, make([]byte, 1024*1024), 0644)
+	c.Assert(err, gc.IsNil)
+	// A directory that can't be removed is left in place of a backup.
+	err = os.MkdirAll(filepath.Join(path+".1", "keep"), 0755)
+	c.Assert(err, gc.IsNil)
+	l := &cmd.Log{Path: "foo.log", Config: "<root>=INFO", MaxSize: 1, MaxBackups: 1}
+	err = l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Infof("hello")
+	}()
+	select {
+	case <-done:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("logging blocked while rotating the log file")
+	}
+	s.assertLogContent(c, path)
+}
+
+func (s *LogSuite) assertLogContent(c *gc.C, path string) {
+	content, err := ioutil.ReadFile(path)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Matches, `^.* INFO .* hello\n`)
+}
+
+func (s *RotatingFileSuite) TestCompressionFailureKeepsLogging(c *gc.C) {
+	// The rotated file can't be compressed over a directory, which is
+	// left in place because an uncompressed backup hides it.
+	err := ioutil.WriteFile(s.path+".1", []byte("old\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	err = os.MkdirAll(filepath.Join(s.path+".1.gz", "keep"), 0755)
+	c.Assert(err, gc.IsNil)
+	f := s.open(c, 0, 0, true)
+	_, err = fmt.Fprint(f, "first\n")
+	c.Assert(err, gc.IsNil)
+	_, err = fmt.Fprint(f, "second\n")
+	c.Assert(err, gc.IsNil)
+
+	// The failure is reported by the next rotation, and then by Close.
+	_, err = fmt.Fprint(f, "third\n")
+	c.Assert(err, gc.ErrorMatches, ".*foo.log.1.gz: is a directory")
+	err = f.Close()
+	c.Assert(err, gc.ErrorMatches, ".*foo.log.1.gz: is a directory")
+	s.assertContent(c, s.path, "third\n")
+	s.assertContent(c, s.path+".1", "second\n")
+	s.assertContent(c, s.path+".2", "first\n")
+	s.assertContent(c, s.path+".3", "old\n")
+}
+
+func (s *RotatingFileSuite) TestWriteAfterClose(c *gc.C) {
+	f := s.open(c, 0, 0, false)
+	err := f.Close()
+	c.Assert(err, gc.IsNil)
+	err = os.Remove(s.path)
+	c.Assert(err, gc.IsNil)
+
+	_, err = fmt.Fprint(f, "late\n")
+	c.Assert(err, gc.ErrorMatches, `log file ".*foo.log" is closed`)
+	_, err = os.Stat(s.path)
+	c.Assert(os.IsNotExist(err), gc.Equals, true)
+}
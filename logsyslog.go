@@ -0,0 +1,62 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/juju/loggo"
+)
+
+// LogTargetSyslog is the Log.Target value that forwards log records to
+// the local syslog daemon.
+const LogTargetSyslog = "syslog"
+
+// defaultSyslogFacility is used when Log.SyslogFacility is empty.
+const defaultSyslogFacility = "daemon"
+
+// syslogger is the subset of *syslog.Writer used to forward log records,
+// one method per syslog severity.
+type syslogger interface {
+	Crit(m string) error
+	Err(m string) error
+	Warning(m string) error
+	Info(m string) error
+	Debug(m string) error
+}
+
+// connectSyslog is newSyslogWriter, replaced in tests.
+var connectSyslog = newSyslogWriter
+
+type syslogWriter struct {
+	writer syslogger
+}
+
+// Close closes the connection to syslog.
+func (w *syslogWriter) Close() error {
+	if closer, ok := w.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Write implements loggo.Writer. The timestamp is left for syslog to
+// record, and the loggo level is mapped onto the closest syslog severity.
+func (w *syslogWriter) Write(entry loggo.Entry) {
+	message := fmt.Sprintf("%s %s:%d %s", entry.Module, filepath.Base(entry.Filename), entry.Line, entry.Message)
+	switch entry.Level {
+	case loggo.CRITICAL:
+		w.writer.Crit(message)
+	case loggo.ERROR:
+		w.writer.Err(message)
+	case loggo.WARNING:
+		w.writer.Warning(message)
+	case loggo.INFO:
+		w.writer.Info(message)
+	default:
+		w.writer.Debug(message)
+	}
+}
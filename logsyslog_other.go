@@ -0,0 +1,17 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+//go:build windows || plan9
+// +build windows plan9
+
+package cmd
+
+import (
+	"errors"
+)
+
+// newSyslogWriter always fails, as syslog is not available on this
+// platform.
+func newSyslogWriter(facility, tag string) (*syslogWriter, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type SyslogSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&SyslogSuite{})
+
+type fakeSyslogger struct {
+	messages []string
+	closed   bool
+}
+
+func (f *fakeSyslogger) record(severity, m string) error {
+	f.messages = append(f.messages, severity+" "+m)
+	return nil
+}
+
+func (f *fakeSyslogger) Crit(m string) error    { return f.record("crit", m) }
+func (f *fakeSyslogger) Err(m string) error     { return f.record("err", m) }
+func (f *fakeSyslogger) Warning(m string) error { return f.record("warning", m) }
+func (f *fakeSyslogger) Info(m string) error    { return f.record("info", m) }
+func (f *fakeSyslogger) Debug(m string) error   { return f.record("debug", m) }
+
+func (f *fakeSyslogger) Close() error {
+	f.closed = true
+	return nil
+}
+
+func (s *SyslogSuite) TestSeverityMapping(c *gc.C) {
+	fake := &fakeSyslogger{}
+	w := cmd.NewSyslogWriter(fake)
+	for _, level := range []loggo.Level{
+		loggo.CRITICAL, loggo.ERROR, loggo.WARNING, loggo.INFO, loggo.DEBUG, loggo.TRACE,
+	} {
+		w.Write(loggo.Entry{
+			Level:     level,
+			Module:    "juju.test",
+			Filename:  "/path/to/file.go",
+			Line:      42,
+			Timestamp: time.Now(),
+			Message:   "hello",
+		})
+	}
+	c.Assert(fake.messages, gc.DeepEquals, []string{
+		"crit juju.test file.go:42 hello",
+		"err juju.test file.go:42 hello",
+		"warning juju.test file.go:42 hello",
+		"info juju.test file.go:42 hello",
+		"debug juju.test file.go:42 hello",
+		"debug juju.test file.go:42 hello",
+	})
+}
+
+func (s *SyslogSuite) TestTargetFlag(c *gc.C) {
+	log := newLogWithFlags(c, "", "--log-target", "syslog")
+	c.Assert(log.Target, gc.Equals, cmd.LogTargetSyslog)
+}
+
+func (s *SyslogSuite) TestUnknownTarget(c *gc.C) {
+	l := &cmd.Log{Target: "carrier-pigeon"}
+	err := l.Start(cmdtesting.Context(c))
+	c.Assert(err, gc.ErrorMatches, `unknown log target "carrier-pigeon", expected "syslog"`)
+}
+
+func (s *SyslogSuite) TestUnknownFacility(c *gc.C) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		c.Skip("syslog is not supported on this platform")
+	}
+	l := &cmd.Log{Target: cmd.LogTargetSyslog, SyslogFacility: "bogus"}
+	err := l.Start(cmdtesting.Context(c))
+	c.Assert(err, gc.ErrorMatches, `unknown syslog facility "bogus"`)
+}
+
+func (s *SyslogSuite) TestRestartClosesConnection(c *gc.C) {
+	first := &fakeSyslogger{}
+	s.PatchValue(cmd.ConnectSyslog, cmd.FakeConnectSyslog(first))
+	l := &cmd.Log{Target: cmd.LogTargetSyslog, ShowLog: true, Config: "<root>=INFO"}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("one")
+
+	second := &fakeSyslogger{}
+	s.PatchValue(cmd.ConnectSyslog, cmd.FakeConnectSyslog(second))
+	err = l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Infof("two")
+
+	c.Assert(first.closed, gc.Equals, true)
+	c.Assert(first.messages, gc.HasLen, 1)
+	c.Assert(first.messages[0], gc.Matches, "info juju.test .* one")
+	c.Assert(second.closed, gc.Equals, false)
+	c.Assert(second.messages, gc.HasLen, 1)
+	c.Assert(second.messages[0], gc.Matches, "info juju.test .* two")
+}
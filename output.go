@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/juju/gnuflag"
 	goyaml "gopkg.in/yaml.v2"
@@ -85,6 +87,104 @@ func FormatSmart(writer io.Writer, value interface{}) error {
 	return err
 }
 
+// FormatTabular writes out value as a table, for people rather than
+// scripts to read. A slice of structs has a row for each element and a
+// column for each field; a single struct or a map has a row for each
+// field or key. Fields are named as they are in yaml, so that the names
+// are the same whichever format is chosen. Any other value is written
+// out as with FormatSmart. It is not one of DefaultFormatters; commands
+// that want it add it to the formatters they pass to Output.AddFlags.
+func FormatTabular(writer io.Writer, value interface{}) error {
+	v := indirect(reflect.ValueOf(value))
+	var rows [][]string
+	switch {
+	case v.Kind() == reflect.Struct:
+		names, values := structColumns(v)
+		for i, name := range names {
+			rows = append(rows, []string{name, values[i]})
+		}
+	case v.Kind() == reflect.Map:
+		for _, key := range v.MapKeys() {
+			rows = append(rows, []string{fmt.Sprint(key.Interface()), cellValue(v.MapIndex(key))})
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			return rows[i][0] < rows[j][0]
+		})
+	case v.Kind() == reflect.Slice && indirectType(v.Type().Elem()).Kind() == reflect.Struct:
+		if v.Len() > 0 {
+			names, _ := structColumns(reflect.Zero(indirectType(v.Type().Elem())))
+			rows = append(rows, names)
+		}
+		for i := 0; i < v.Len(); i++ {
+			element := indirect(v.Index(i))
+			if !element.IsValid() {
+				// Nil elements have no fields to show.
+				continue
+			}
+			_, values := structColumns(element)
+			rows = append(rows, values)
+		}
+	default:
+		return FormatSmart(writer, value)
+	}
+	tw := tabwriter.NewWriter(writer, 0, 8, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// structColumns returns the yaml names of the exported fields of the
+// struct v, and their values.
+func structColumns(v reflect.Value) (names, values []string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		names = append(names, name)
+		values = append(values, cellValue(v.Field(i)))
+	}
+	return names, values
+}
+
+// cellValue returns v as it is written in a table cell.
+func cellValue(v reflect.Value) string {
+	v = indirect(v)
+	if !v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// indirect follows pointers and interfaces in v to the value they hold,
+// which is the zero Value if any of them is nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// indirectType returns the type that t points to, if it is a pointer.
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
 // TypeFormatter describes a formatting type that can define if a type is
 // serialisable.
 type TypeFormatter struct {
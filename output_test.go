@@ -4,6 +4,8 @@
 package cmd_test
 
 import (
+	"bytes"
+
 	"github.com/juju/gnuflag"
 	gc "gopkg.in/check.v1"
 
@@ -133,6 +135,50 @@ var outputTests = map[string][]struct {
 	},
 }
 
+var tabularTests = []struct {
+	value  interface{}
+	output string
+}{
+	{nil, ""},
+	{"hello", "hello\n"},
+	{true, "True\n"},
+	{[]string{"blam", "dink"}, "blam\ndink\n"},
+	{defaultValue, "juju    1\npuppet  false\n"},
+	{&defaultValue, "juju    1\npuppet  false\n"},
+	{map[string]int{"foo": 1, "barbaz": 2}, "barbaz  2\nfoo     1\n"},
+	{[]tabularValue{}, ""},
+	{[]tabularValue{
+		{Name: "machine-0", Status: "started", Count: 10},
+		{Name: "unit-mysql-0", Status: "error"},
+	}, "name          state    count\nmachine-0     started  10\nunit-mysql-0  error    0\n"},
+	{[]*tabularValue{{Name: "machine-0", Status: "started"}}, "name       state    count\nmachine-0  started  0\n"},
+	{[]*tabularValue{nil, {Name: "machine-0", Status: "started"}, nil}, "name       state    count\nmachine-0  started  0\n"},
+}
+
+func (s *CmdSuite) TestFormatTabular(c *gc.C) {
+	for i, t := range tabularTests {
+		c.Logf("test %d", i)
+		var buf bytes.Buffer
+		err := cmd.FormatTabular(&buf, t.value)
+		c.Check(err, gc.IsNil)
+		c.Check(buf.String(), gc.Equals, t.output)
+	}
+}
+
+func (s *CmdSuite) TestFormatTabularNotDefault(c *gc.C) {
+	_, ok := cmd.DefaultFormatters["tabular"]
+	c.Assert(ok, gc.Equals, false)
+}
+
+// tabularValue has fields named differently in yaml, and one that isn't
+// written out at all.
+type tabularValue struct {
+	Name   string
+	Status string `yaml:"state" json:"state"`
+	Count  int    `yaml:"count,omitempty"`
+	Secret string `yaml:"-"`
+}
+
 func (s *CmdSuite) TestOutputFormat(c *gc.C) {
 	for format, tests := range outputTests {
 		c.Logf("format %s", format)
@@ -0,0 +1,56 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// PluginCallback returns a MissingCallback that runs the executable named
+// prefix followed by the subcommand, looked up on the PATH, in place of a
+// subcommand that isn't registered. The plugin gets the remaining
+// arguments, the context's directory and standard streams, and the
+// process environment along with the context's variables. If the plugin
+// exits with a non-zero code, the error returned is an
+// RcPassthroughError, so that Main exits with the same code. A plugin
+// killed by a signal makes Main exit with ExitFatal.
+//
+// Subcommands containing a path separator are never run as plugins.
+func PluginCallback(prefix string) MissingCallback {
+	return func(ctx *Context, subcommand string, args []string) error {
+		// LookPath would treat such a name as a path relative to the
+		// current directory rather than search the PATH.
+		if strings.ContainsAny(subcommand, "/"+string(os.PathSeparator)) {
+			return DefaultUnrecognizedCommand(subcommand)
+		}
+		path, err := exec.LookPath(prefix + subcommand)
+		if err != nil {
+			return DefaultUnrecognizedCommand(subcommand)
+		}
+		command := exec.Command(path, args...)
+		command.Dir = ctx.Dir
+		command.Stdin = ctx.Stdin
+		command.Stdout = ctx.Stdout
+		command.Stderr = ctx.Stderr
+		command.Env = os.Environ()
+		keys := make([]string, 0, len(ctx.Env))
+		for key := range ctx.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			command.Env = append(command.Env, key+"="+ctx.Env[key])
+		}
+		err = command.Run()
+		// ExitCode is -1 if the plugin was killed by a signal, which is
+		// left to be reported as an ordinary error.
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() >= 0 {
+			return NewRcPassthroughError(exitErr.ExitCode())
+		}
+		return err
+	}
+}
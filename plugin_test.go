@@ -0,0 +1,95 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type PluginSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&PluginSuite{})
+
+func (s *PluginSuite) SetUpTest(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("plugins are shell scripts")
+	}
+	s.LoggingCleanupSuite.SetUpTest(c)
+	dir := c.MkDir()
+	s.PatchEnvironment("PATH", dir)
+	err := ioutil.WriteFile(filepath.Join(dir, "jujud-inspect"), []byte(`#!/bin/sh
+echo "inspect $@ in $PWD with ${JUJU_TEST}"
+exit $1
+`), 0755)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, "jujud-crash"), []byte(`#!/bin/sh
+kill -9 $$
+`), 0755)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *PluginSuite) newSuperCommand() *cmd.SuperCommand {
+	return cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:            "jujud",
+		MissingCallback: cmd.PluginCallback("jujud-"),
+	})
+}
+
+func (s *PluginSuite) TestRunsPlugin(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	ctx.Setenv("JUJU_TEST", "context")
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"inspect", "0", "--foo"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "inspect 0 --foo in "+ctx.Dir+" with context\n")
+}
+
+func (s *PluginSuite) TestPassesExitCode(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"inspect", "3"})
+	c.Assert(code, gc.Equals, 3)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+func (s *PluginSuite) TestNotFound(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"missing"})
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "ERROR unrecognized command: jujud missing\n")
+}
+
+func (s *PluginSuite) TestKilledBySignal(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"crash"})
+	c.Assert(code, gc.Equals, cmd.ExitFatal)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "ERROR signal: killed\n")
+}
+
+func (s *PluginSuite) TestPathNotSearched(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	err := os.Mkdir(filepath.Join(ctx.Dir, "jujud-bin"), 0755)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(ctx.Dir, "jujud-bin", "inspect"), []byte("#!/bin/sh\necho local\n"), 0755)
+	c.Assert(err, gc.IsNil)
+	cwd, err := os.Getwd()
+	c.Assert(err, gc.IsNil)
+	err = os.Chdir(ctx.Dir)
+	c.Assert(err, gc.IsNil)
+	defer os.Chdir(cwd)
+
+	code := cmd.Main(s.newSuperCommand(), ctx, []string{"bin/inspect"})
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "ERROR unrecognized command: jujud bin/inspect\n")
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// isTerminal reports whether r is an interactive terminal.
+var isTerminal = func(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	return ok && isatty.IsTerminal(f.Fd())
+}
+
+// Prompt writes question to Stderr and returns the line read from Stdin
+// in answer, without its line ending.
+func (ctx *Context) Prompt(question string) (string, error) {
+	fmt.Fprint(ctx.Stderr, question)
+	// Read a byte at a time so that nothing after the line is consumed,
+	// leaving it for any later prompts.
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := ctx.Stdin.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err == io.EOF {
+			if len(line) == 0 {
+				return "", fmt.Errorf("no answer given")
+			}
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
+
+// Confirm asks whether to go ahead with what is described by question,
+// returning true if the answer is yes. If the command was run with --yes
+// the question is not asked. Otherwise, Stdin must be a terminal, so that
+// scripts don't wait for an answer that will never come.
+func (ctx *Context) Confirm(question string) (bool, error) {
+	if ctx.assumeYes {
+		return true, nil
+	}
+	if !isTerminal(ctx.Stdin) {
+		return false, fmt.Errorf("cannot ask for confirmation without a terminal, use --yes to proceed")
+	}
+	answer, err := ctx.Prompt(question + " (y/N): ")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
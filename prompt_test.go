@@ -0,0 +1,109 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"io"
+	"strings"
+
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type PromptSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&PromptSuite{})
+
+func (s *PromptSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.PatchValue(cmd.IsTerminal, func(io.Reader) bool { return true })
+}
+
+func (s *PromptSuite) TestPrompt(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	ctx.Stdin = strings.NewReader("first\r\nsecond")
+	answer, err := ctx.Prompt("name? ")
+	c.Assert(err, gc.IsNil)
+	c.Assert(answer, gc.Equals, "first")
+	answer, err = ctx.Prompt("again? ")
+	c.Assert(err, gc.IsNil)
+	c.Assert(answer, gc.Equals, "second")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "name? again? ")
+
+	_, err = ctx.Prompt("more? ")
+	c.Assert(err, gc.ErrorMatches, "no answer given")
+}
+
+func (s *PromptSuite) TestConfirm(c *gc.C) {
+	for _, test := range []struct {
+		input    string
+		expected bool
+	}{
+		{"y\n", true},
+		{"Yes\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"whatever\n", false},
+	} {
+		ctx := cmdtesting.Context(c)
+		ctx.Stdin = strings.NewReader(test.input)
+		ok, err := ctx.Confirm("Reset the state?")
+		c.Check(err, gc.IsNil)
+		c.Check(ok, gc.Equals, test.expected, gc.Commentf("input %q", test.input))
+		c.Check(cmdtesting.Stderr(ctx), gc.Equals, "Reset the state? (y/N): ")
+	}
+}
+
+func (s *PromptSuite) TestConfirmWithoutTerminal(c *gc.C) {
+	s.PatchValue(cmd.IsTerminal, func(io.Reader) bool { return false })
+	ctx := cmdtesting.Context(c)
+	ctx.Stdin = strings.NewReader("y\n")
+	ok, err := ctx.Confirm("Reset the state?")
+	c.Assert(err, gc.ErrorMatches, "cannot ask for confirmation without a terminal, use --yes to proceed")
+	c.Assert(ok, gc.Equals, false)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+// confirmCommand asks for confirmation before doing anything.
+type confirmCommand struct {
+	cmd.CommandBase
+}
+
+func (c *confirmCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "reset"}
+}
+
+func (c *confirmCommand) Run(ctx *cmd.Context) error {
+	ok, err := ctx.Confirm("Reset the state?")
+	if err != nil {
+		return err
+	}
+	if ok {
+		ctx.Infof("reset")
+	}
+	return nil
+}
+
+func (s *PromptSuite) TestYesFlag(c *gc.C) {
+	s.PatchValue(cmd.IsTerminal, func(io.Reader) bool { return false })
+	newSuper := func() *cmd.SuperCommand {
+		jc := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujud", YesFlag: true})
+		jc.Register(&confirmCommand{})
+		return jc
+	}
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(newSuper(), ctx, []string{"reset", "--yes"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "reset\n")
+
+	ctx = cmdtesting.Context(c)
+	code = cmd.Main(newSuper(), ctx, []string{"reset"})
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "ERROR cannot ask for confirmation without a terminal, use --yes to proceed\n")
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestionDistance is the greatest number of single character edits
+// a registered name can be from an unrecognized one to be suggested.
+const maxSuggestionDistance = 2
+
+// maxSuggestions is the most names suggested for an unrecognized one.
+const maxSuggestions = 3
+
+// suggestCommands returns the names of the subcommands and aliases of
+// super that are closest to name, nearest first. Hidden and deprecated
+// commands are never suggested.
+func suggestCommands(super *SuperCommand, name string) []string {
+	distances := make(map[string]int)
+	var names []string
+	for subName, action := range super.subcmds {
+		if action.hidden {
+			continue
+		}
+		if deprecated, _ := action.Deprecated(); deprecated {
+			continue
+		}
+		if d := editDistance(name, subName); d <= maxSuggestionDistance {
+			distances[subName] = d
+			names = append(names, subName)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if distances[names[i]] != distances[names[j]] {
+			return distances[names[i]] < distances[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxSuggestions {
+		names = names[:maxSuggestions]
+	}
+	return names
+}
+
+// didYouMean returns a sentence suggesting the given names, or "" if
+// there are none.
+func didYouMean(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	if len(quoted) == 1 {
+		return fmt.Sprintf("did you mean %s?", quoted[0])
+	}
+	last := len(quoted) - 1
+	return fmt.Sprintf("did you mean %s or %s?", strings.Join(quoted[:last], ", "), quoted[last])
+}
+
+// editDistance returns the Levenshtein distance between a and b: the
+// number of single character insertions, deletions and substitutions
+// needed to turn one into the other.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(t)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/gnuflag"
@@ -52,6 +53,19 @@ func (e *UnrecognizedCommand) Error() string {
 // the requested subcommand isn't found.
 type MissingCallback func(ctx *Context, subcommand string, args []string) error
 
+// CommandUsage describes a single run of a command, for RecordUsage.
+type CommandUsage struct {
+	// Command is the full name of the command that was run, such as
+	// "juju bootstrap". Aliases are recorded under the command's name.
+	Command string
+
+	// Duration is how long the command took to run.
+	Duration time.Duration
+
+	// ExitCode is the code Main exits with for the command's error.
+	ExitCode int
+}
+
 // SuperCommandParams provides a way to have default parameter to the
 // `NewSuperCommand` call.
 type SuperCommandParams struct {
@@ -65,6 +79,12 @@ type SuperCommandParams struct {
 	// is about to run a sub-command.
 	NotifyRun func(cmdName string)
 
+	// RecordUsage, if not nil, is called after a sub-command other than
+	// a nested SuperCommand has run, with its name, how long it took and
+	// the code Main exits with. The arguments it was given are not
+	// recorded. A nested SuperCommand needs its own RecordUsage.
+	RecordUsage func(CommandUsage)
+
 	// NotifyHelp is called just before help is printed, with the
 	// arguments received by the help command. This can be
 	// used, for example, to load command information for external
@@ -95,12 +115,54 @@ type SuperCommandParams struct {
 	// to add flags, or provide short cuts to longer commands.
 	UserAliasesFilename string
 
+	// FlagDefaultsFilename refers to the location of a YAML file that
+	// supplies default values for the subcommands' flags, either at the
+	// top level, for every subcommand with a flag of that name, or in a
+	// section named after a subcommand. Values given on the command line
+	// take precedence. The file can be changed with the --config flag;
+	// it is ignored if it doesn't exist, unless it was named that way.
+	// It doesn't set --help or --description, nor the flags of commands
+	// under a nested SuperCommand, which uses its own parameters.
+	FlagDefaultsFilename string
+
+	// FlagEnvPrefix, if set, allows environment variables to supply the
+	// values of flags that aren't given on the command line. The variable
+	// for a flag is named after the prefix and the flag, so with a prefix
+	// of "JUJUD" the --log-file flag can be set with JUJUD_LOG_FILE.
+	// Values from the environment take precedence over those from
+	// FlagDefaultsFilename. The variable for --config names the flag
+	// defaults file instead, and never sets a subcommand's own --config.
+	// Variables in the Context's Env take precedence over the process
+	// environment. As with FlagDefaultsFilename, --help, --description
+	// and the flags of commands under a nested SuperCommand are not set.
+	FlagEnvPrefix string
+
 	// FlagKnownAs allows different projects to customise what their flags are
 	// known as, e.g. 'flag', 'option', 'item'. All error/log messages
 	// will use that name when referring to an individual items/flags in this command.
 	// For example, if this value is 'option', the default message 'value for flag'
 	// will become 'value for option'.
 	FlagKnownAs string
+
+	// Completion, if true, registers a "completion" subcommand that
+	// writes a bash, zsh or fish script enabling tab completion of the
+	// registered subcommands and their flags.
+	Completion bool
+
+	// Documentation, if true, registers a hidden "documentation"
+	// subcommand that renders a man page for each registered command
+	// from its Info and flags.
+	Documentation bool
+
+	// YesFlag, if true, adds a --yes flag common to all subcommands, that
+	// answers yes to any question asked with Context.Confirm.
+	YesFlag bool
+
+	// TimingFlag, if true, adds a --show-timing flag common to all
+	// subcommands, that writes how long parsing the command line,
+	// initialising the command and running it each took to stderr once
+	// the command finishes.
+	TimingFlag bool
 }
 
 // FlagAdder represents a value that has associated flags.
@@ -125,9 +187,16 @@ func NewSuperCommand(params SuperCommandParams) *SuperCommand {
 		version:             params.Version,
 		versionDetail:       params.VersionDetail,
 		notifyRun:           params.NotifyRun,
+		recordUsage:         params.RecordUsage,
 		notifyHelp:          params.NotifyHelp,
 		userAliasesFilename: params.UserAliasesFilename,
+		flagDefaultsFile:    params.FlagDefaultsFilename,
+		flagEnvPrefix:       params.FlagEnvPrefix,
 		FlagKnownAs:         params.FlagKnownAs,
+		completion:          params.Completion,
+		documentation:       params.Documentation,
+		yesFlag:             params.YesFlag,
+		timingFlag:          params.TimingFlag,
 	}
 	command.init()
 	return command
@@ -151,6 +220,8 @@ type commandReference struct {
 	command Command
 	alias   string
 	check   DeprecationCheck
+	// hidden commands can be run, but are not listed with the others.
+	hidden bool
 }
 
 // SuperCommand is a Command that selects a subcommand and assumes its
@@ -170,6 +241,9 @@ type SuperCommand struct {
 	usagePrefix         string
 	userAliasesFilename string
 	userAliases         map[string][]string
+	flagDefaultsFile    string
+	flagEnvPrefix       string
+	ctx                 *Context
 	subcmds             map[string]commandReference
 	help                *helpCommand
 	commonflags         *gnuflag.FlagSet
@@ -181,7 +255,14 @@ type SuperCommand struct {
 	noAlias             bool
 	missingCallback     MissingCallback
 	notifyRun           func(string)
+	recordUsage         func(CommandUsage)
 	notifyHelp          func([]string)
+	completion          bool
+	documentation       bool
+	yesFlag             bool
+	assumeYes           bool
+	timingFlag          bool
+	showTiming          bool
 
 	// FlagKnownAs allows different projects to customise what their flags are
 	// known as, e.g. 'flag', 'option', 'item'. All error/log messages
@@ -217,6 +298,19 @@ func (c *SuperCommand) init() {
 		}
 	}
 
+	if c.completion {
+		c.subcmds["completion"] = commandReference{
+			command: newCompletionCommand(c),
+		}
+	}
+
+	if c.documentation {
+		c.subcmds["documentation"] = commandReference{
+			command: newDocumentationCommand(c),
+			hidden:  true,
+		}
+	}
+
 	c.userAliases = ParseAliasFile(c.userAliasesFilename)
 }
 
@@ -263,6 +357,17 @@ func (c *SuperCommand) RegisterDeprecated(subcmd Command, check DeprecationCheck
 	}
 }
 
+// RegisterHidden makes a subcommand available for use on the command line
+// without it being listed in help or completion, for internal commands that
+// are not meant for general use.
+func (c *SuperCommand) RegisterHidden(subcmd Command) {
+	info := subcmd.Info()
+	c.insert(commandReference{name: info.Name, command: subcmd, hidden: true})
+	for _, name := range info.Aliases {
+		c.insert(commandReference{name: name, command: subcmd, alias: info.Name, hidden: true})
+	}
+}
+
 // RegisterAlias makes an existing subcommand available under another name.
 // If `check` is supplied, and the result of the `Obsolete` call is true,
 // then the alias is not registered.
@@ -329,15 +434,16 @@ func (c *SuperCommand) describeCommands(simple bool) string {
 		lineFormat = "%-*s  %s"
 		outputFormat = "%s"
 	}
-	cmds := make([]string, len(c.subcmds))
-	i := 0
+	cmds := make([]string, 0, len(c.subcmds))
 	longest := 0
-	for name := range c.subcmds {
+	for name, action := range c.subcmds {
+		if action.hidden {
+			continue
+		}
 		if len(name) > longest {
 			longest = len(name)
 		}
-		cmds[i] = name
-		i++
+		cmds = append(cmds, name)
 	}
 	sort.Strings(cmds)
 	var result []string
@@ -394,6 +500,12 @@ func (c *SuperCommand) SetCommonFlags(f *gnuflag.FlagSet) {
 	if c.globalFlags != nil {
 		c.globalFlags.AddFlags(f)
 	}
+	if c.yesFlag {
+		f.BoolVar(&c.assumeYes, "yes", false, "Answer yes to any confirmation prompts")
+	}
+	if c.timingFlag {
+		f.BoolVar(&c.showTiming, "show-timing", false, "Show how long each phase of the command took")
+	}
 	f.BoolVar(&c.showHelp, "h", false, helpPurpose)
 	f.BoolVar(&c.showHelp, "help", false, "")
 	// In the case where we are providing the basis for a plugin,
@@ -421,6 +533,9 @@ func (c *SuperCommand) SetFlags(f *gnuflag.FlagSet) {
 	if c.userAliasesFilename != "" {
 		f.BoolVar(&c.noAlias, "no-alias", false, "do not process command aliases when running this command")
 	}
+	if c.flagDefaultsFile != "" {
+		f.StringVar(&c.flagDefaultsFile, "config", c.flagDefaultsFile, "read default flag values from this YAML file")
+	}
 	c.flags = f
 }
 
@@ -461,6 +576,9 @@ func (c *SuperCommand) Init(args []string) error {
 			// Yes return here, no Init called on missing Command.
 			return nil
 		}
+		if suggestion := didYouMean(suggestCommands(c, args[0])); suggestion != "" {
+			return fmt.Errorf("unrecognized command: %s %s, %s", c.Name, args[0], suggestion)
+		}
 		return fmt.Errorf("unrecognized command: %s %s", c.Name, args[0])
 	}
 	args = args[1:]
@@ -469,12 +587,30 @@ func (c *SuperCommand) Init(args []string) error {
 		f := gnuflag.NewFlagSetWithFlagKnownAs(c.Info().Name, gnuflag.ContinueOnError, FlagAlias(subcmd, "flag"))
 		f.SetOutput(ioutil.Discard)
 		subcmd.SetFlags(f)
+		if sub, ok := subcmd.(*SuperCommand); ok {
+			sub.ctx = c.ctx
+			// The flags common to this SuperCommand's subcommands apply
+			// to those of the nested SuperCommand as well, so that they
+			// can be given after the full command name.
+			if sub.commonflags != nil {
+				c.commonflags.VisitAll(func(flag *gnuflag.Flag) {
+					if sub.commonflags.Lookup(flag.Name) == nil {
+						sub.commonflags.Var(flag.Value, flag.Name, flag.Usage)
+					}
+				})
+			}
+		}
 	} else {
 		subcmd.SetFlags(c.commonflags)
 	}
 	if err := c.commonflags.Parse(subcmd.AllowInterspersedFlags(), args); err != nil {
 		return err
 	}
+	if !subcmd.IsSuperCommand() {
+		if err := c.applyFlagDefaults(subcmd.Info().Name); err != nil {
+			return err
+		}
+	}
 	args = c.commonflags.Args()
 	if c.showHelp {
 		// We want to treat help for the command the same way we would if we went "help foo".
@@ -484,6 +620,45 @@ func (c *SuperCommand) Init(args []string) error {
 	return c.action.command.Init(args)
 }
 
+// applyFlagDefaults sets the flags of the selected subcommand to the
+// values from the flag defaults file and then the environment, apart
+// from those given on the command line. It is called once the command
+// line has been parsed, so that flags that accumulate values don't add
+// the defaults to those given.
+func (c *SuperCommand) applyFlagDefaults(command string) error {
+	if c.flagDefaultsFile == "" && c.flagEnvPrefix == "" {
+		return nil
+	}
+	set := setFlags(c.flags, c.commonflags)
+	if c.flagDefaultsFile != "" {
+		// Only the SuperCommand's own --config names the defaults file;
+		// the subcommand may have a --config flag of its own.
+		path, mustExist := c.flagDefaultsFile, setFlags(c.flags)["config"]
+		if value, ok := lookupFlagEnv(c.ctx, c.flagEnvPrefix, "config"); ok && !mustExist {
+			path, mustExist = value, true
+		}
+		defaults, err := readFlagDefaults(path, mustExist)
+		if err != nil {
+			return err
+		}
+		if defaults != nil {
+			if err := defaults.apply(c.commonflags, command, set); err != nil {
+				return err
+			}
+		}
+	}
+	return applyFlagEnv(c.ctx, c.commonflags, c.flagEnvPrefix, set)
+}
+
+// fullName returns the name of the SuperCommand, prefixed with UsagePrefix
+// unless that is the same.
+func (c *SuperCommand) fullName() string {
+	if c.usagePrefix != "" && c.usagePrefix != c.Name {
+		return c.usagePrefix + " " + c.Name
+	}
+	return c.Name
+}
+
 // Run executes the subcommand that was selected in Init.
 func (c *SuperCommand) Run(ctx *Context) error {
 	if c.showDescription {
@@ -498,6 +673,17 @@ func (c *SuperCommand) Run(ctx *Context) error {
 		panic("Run: missing subcommand; Init failed or not called")
 	}
 
+	if c.assumeYes {
+		ctx.assumeYes = true
+	}
+	if c.showTiming {
+		start := time.Now()
+		defer func() {
+			ctx.addTiming("run", start)
+			ctx.writeTimings()
+		}()
+	}
+
 	// Set the serialisable state on the context, by checking the common global
 	// formatting directive. Set this early enough, so that everyone can take
 	// appropriate action further down stream.
@@ -507,20 +693,31 @@ func (c *SuperCommand) Run(ctx *Context) error {
 		if err := c.Log.Start(ctx); err != nil {
 			return err
 		}
+		defer c.Log.Flush()
 	}
 
 	if c.notifyRun != nil {
-		name := c.Name
-		if c.usagePrefix != "" && c.usagePrefix != name {
-			name = c.usagePrefix + " " + name
-		}
-		c.notifyRun(name)
+		c.notifyRun(c.fullName())
 	}
 	if deprecated, replacement := c.action.Deprecated(); deprecated {
-		ctx.Infof("WARNING: %q is deprecated, please use %q", c.action.name, replacement)
+		ctx.Infof("%s", deprecationWarning(c.action.name, replacement, removalVersion(c.action.check)))
+	}
+	if c.commonflags != nil {
+		warnDeprecatedFlags(ctx, c.commonflags)
+	}
+
+	// Commands such as help clear the selected command while they run,
+	// so keep hold of it.
+	command := c.action.command
+	start := time.Now()
+	err := command.Run(ctx)
+	if c.recordUsage != nil && !command.IsSuperCommand() {
+		c.recordUsage(CommandUsage{
+			Command:  c.fullName() + " " + command.Info().Name,
+			Duration: time.Since(start),
+			ExitCode: ExitCode(err),
+		})
 	}
-
-	err := c.action.command.Run(ctx)
 	if err != nil && !IsErrSilent(err) {
 		// Handle formatting when displaying errors.
 		handleErr := c.handleErrorForMachineFormats(ctx)
@@ -537,7 +734,9 @@ func (c *SuperCommand) Run(ctx *Context) error {
 		logger.Debugf("error stack: \n%v", errors.ErrorStack(err))
 
 		// Err has been logged above, we can make the err silent so it does not log again in cmd/main
-		if !IsRcPassthroughError(err) {
+		if code := ExitCode(err); code != ExitFatal {
+			err = NewRcPassthroughError(code)
+		} else {
 			err = ErrSilent
 		}
 	} else {
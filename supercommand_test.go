@@ -524,7 +524,7 @@ func (s *SuperCommandSuite) TestRegisterSuperAlias(c *gc.C) {
 			stderr: "WARNING: \"bar-dep\" is deprecated, please use \"bar foo\"\n",
 		}, {
 			args:   []string{"bar-ob", "arg"},
-			stderr: "ERROR unrecognized command: jujutest bar-ob\n",
+			stderr: "ERROR unrecognized command: jujutest bar-ob, did you mean \"bar-foo\"?\n",
 			code:   2,
 		},
 	} {
@@ -601,6 +601,140 @@ func (s *SuperCommandSuite) TestRegisterDeprecated(c *gc.C) {
 	}
 }
 
+func (s *SuperCommandSuite) TestRegisterHidden(c *gc.C) {
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name: "jujutest",
+	})
+	jc.Register(&simpleAlias{simple{name: "test-visible"}})
+	jc.RegisterHidden(&simpleAlias{simple{name: "test-hidden"}})
+
+	for _, args := range [][]string{
+		{"test-hidden", "arg"},
+		{"test-hidden-alias", "arg"},
+	} {
+		ctx := cmdtesting.Context(c)
+		code := cmd.Main(jc, ctx, args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(cmdtesting.Stdout(ctx), gc.Equals, "test-hidden arg\n")
+	}
+
+	jc = cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name: "jujutest",
+	})
+	jc.Register(&simpleAlias{simple{name: "test-visible"}})
+	jc.RegisterHidden(&simpleAlias{simple{name: "test-hidden"}})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(jc, ctx, []string{"help", "commands"})
+	c.Check(code, gc.Equals, 0)
+	c.Check(cmdtesting.Stdout(ctx), gc.Equals, `help                Show help on a command or other topic.
+test-visible        to be simple with an alias
+test-visible-alias  Alias for 'test-visible'.
+`)
+}
+
+func (s *SuperCommandSuite) TestShowTiming(c *gc.C) {
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:       "jujutest",
+		TimingFlag: true,
+	})
+	jc.Register(&TestCommand{Name: "blah"})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(jc, ctx, []string{"blah", "--option", "error", "--show-timing"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(cmdtesting.Stderr(ctx), gc.Matches, `ERROR BAM!
+parse took .*
+init took .*
+run took .*
+`)
+}
+
+func (s *SuperCommandSuite) TestNoShowTiming(c *gc.C) {
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:       "jujutest",
+		TimingFlag: true,
+	})
+	jc.Register(&TestCommand{Name: "blah"})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(jc, ctx, []string{"blah", "--option", "success"})
+	c.Check(code, gc.Equals, 0)
+	c.Check(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+func (s *SuperCommandSuite) TestRecordUsage(c *gc.C) {
+	var usages []cmd.CommandUsage
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		UsagePrefix: "juju",
+		Name:        "jujutest",
+		RecordUsage: func(usage cmd.CommandUsage) {
+			usages = append(usages, usage)
+		},
+	})
+	jc.Register(&TestCommand{Name: "blah", Aliases: []string{"bl"}})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(jc, ctx, []string{"bl", "--option", "transient-error"})
+	c.Assert(code, gc.Equals, cmd.ExitTransient)
+	c.Assert(usages, gc.HasLen, 1)
+	c.Check(usages[0].Command, gc.Equals, "juju jujutest blah")
+	c.Check(usages[0].ExitCode, gc.Equals, cmd.ExitTransient)
+}
+
+func (s *SuperCommandSuite) TestUnrecognizedCommandSuggestions(c *gc.C) {
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name: "jujutest",
+	})
+	jc.Register(&simpleAlias{simple{name: "status"}})
+	jc.Register(&simple{name: "start"})
+	jc.Register(&simple{name: "stop"})
+	jc.RegisterHidden(&simple{name: "stat"})
+	jc.RegisterDeprecated(&simple{name: "statue"}, deprecate{replacement: "status"})
+
+	for _, test := range []struct {
+		name   string
+		stderr string
+	}{{
+		name:   "sttus",
+		stderr: `ERROR unrecognized command: jujutest sttus, did you mean "status"?` + "\n",
+	}, {
+		name:   "status-alais",
+		stderr: `ERROR unrecognized command: jujutest status-alais, did you mean "status-alias"?` + "\n",
+	}, {
+		name:   "sta",
+		stderr: `ERROR unrecognized command: jujutest sta, did you mean "start" or "stop"?` + "\n",
+	}, {
+		name:   "stap",
+		stderr: `ERROR unrecognized command: jujutest stap, did you mean "stop" or "start"?` + "\n",
+	}, {
+		name:   "stas",
+		stderr: `ERROR unrecognized command: jujutest stas, did you mean "start", "status" or "stop"?` + "\n",
+	}, {
+		name:   "discombobulate",
+		stderr: "ERROR unrecognized command: jujutest discombobulate\n",
+	}} {
+		c.Logf("command %q", test.name)
+		ctx := cmdtesting.Context(c)
+		code := cmd.Main(jc, ctx, []string{test.name})
+		c.Check(code, gc.Equals, 2)
+		c.Check(cmdtesting.Stderr(ctx), gc.Equals, test.stderr)
+	}
+}
+
+func (s *SuperCommandSuite) TestRecordUsageHelp(c *gc.C) {
+	var usages []cmd.CommandUsage
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name: "jujutest",
+		RecordUsage: func(usage cmd.CommandUsage) {
+			usages = append(usages, usage)
+		},
+	})
+	jc.Register(&TestCommand{Name: "blah"})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(jc, ctx, []string{"help"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(usages, gc.HasLen, 1)
+	c.Check(usages[0].Command, gc.Equals, "jujutest help")
+	c.Check(usages[0].ExitCode, gc.Equals, 0)
+}
+
 func (s *SuperCommandSuite) TestGlobalFlagsBeforeCommand(c *gc.C) {
 	flag := ""
 	sc := cmd.NewSuperCommand(cmd.SuperCommandParams{
@@ -811,3 +945,23 @@ func (s *SuperCommandSuite) TestFindClosestSubCommandReturnsConsistentResults(c
 	c.Assert(ok, gc.Equals, true)
 	c.Assert(name, gc.Equals, "help")
 }
+
+func (s *SuperCommandSuite) TestNestedSuperCommandInheritsCommonFlags(c *gc.C) {
+	log := &cmd.Log{}
+	jc := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name: "jujud",
+		Log:  log,
+	})
+	introspect := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:    "introspect",
+		Purpose: "Introspect the agent.",
+	})
+	introspect.Register(&TestCommand{Name: "report"})
+	jc.Register(introspect)
+
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(jc, ctx, []string{"introspect", "report", "--option", "done", "--show-log"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "done\n")
+	c.Assert(log.ShowLog, gc.Equals, true)
+}
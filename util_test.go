@@ -61,6 +61,14 @@ func (c *TestCommand) Run(ctx *cmd.Context) error {
 		return errors.New("BAM!")
 	case "silent-error":
 		return cmd.ErrSilent
+	case "transient-error":
+		return cmd.NewTransientError(errors.New("BAM!"))
+	case "config-error":
+		return cmd.NewConfigError(errors.New("BAM!"))
+	case "upgrade-error":
+		return cmd.NewNeedsUpgradeError(errors.New("BAM!"))
+	case "panic":
+		panic("BOOM!")
 	case "echo":
 		_, err := io.Copy(ctx.Stdout, ctx.Stdin)
 		return err
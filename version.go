@@ -4,6 +4,8 @@
 package cmd
 
 import (
+	"runtime"
+
 	"github.com/juju/gnuflag"
 )
 
@@ -17,6 +19,15 @@ type versionCommand struct {
 	showAll bool
 }
 
+// defaultVersionDetail is output by the version command when it is passed
+// --all, if no other detail has been supplied.
+type defaultVersionDetail struct {
+	Version   string `json:"version" yaml:"version"`
+	GoVersion string `json:"go-version" yaml:"go-version"`
+	OS        string `json:"os" yaml:"os"`
+	Arch      string `json:"arch" yaml:"arch"`
+}
+
 func newVersionCommand(version string, versionDetail interface{}) *versionCommand {
 	return &versionCommand{
 		version:       version,
@@ -42,7 +53,16 @@ func (v *versionCommand) SetFlags(f *gnuflag.FlagSet) {
 
 func (v *versionCommand) Run(ctxt *Context) error {
 	if v.showAll {
-		return v.out.Write(ctxt, v.versionDetail)
+		detail := v.versionDetail
+		if detail == nil {
+			detail = defaultVersionDetail{
+				Version:   v.version,
+				GoVersion: runtime.Version(),
+				OS:        runtime.GOOS,
+				Arch:      runtime.GOARCH,
+			}
+		}
+		return v.out.Write(ctxt, detail)
 	}
 	return v.out.Write(ctxt, v.version)
 }
@@ -5,6 +5,7 @@ package cmd_test
 
 import (
 	"fmt"
+	"runtime"
 
 	"github.com/juju/testing"
 	gc "gopkg.in/check.v1"
@@ -69,3 +70,16 @@ func (s *VersionSuite) TestVersionDetailJson(c *gc.C) {
 {"version":"999.888.777","git-commit-hash":"46f1a0bd5592a2f9244ca321b129902a06b53e03","git-tree-state":"dirty"}
 `[1:])
 }
+
+func (s *VersionSuite) TestVersionAllWithoutDetailJson(c *gc.C) {
+	const version = "999.888.777"
+
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(cmd.NewVersionCommand(version, nil), ctx, []string{"--all", "--format", "json"})
+	c.Check(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, fmt.Sprintf(
+		`{"version":%q,"go-version":%q,"os":%q,"arch":%q}`+"\n",
+		version, runtime.Version(), runtime.GOOS, runtime.GOARCH,
+	))
+}